@@ -11,6 +11,8 @@ import (
 	_ "google.golang.org/genproto/googleapis/api/annotations"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	fieldmaskpb "google.golang.org/protobuf/types/known/fieldmaskpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -187,11 +189,2687 @@ func (x *GetUserResponse) GetUser() *PublicUser {
 	return nil
 }
 
+type LookupUserRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Exactly one of username and email must be set.
+	Username      string `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	Email         string `protobuf:"bytes,2,opt,name=email,proto3" json:"email,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LookupUserRequest) Reset() {
+	*x = LookupUserRequest{}
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LookupUserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LookupUserRequest) ProtoMessage() {}
+
+func (x *LookupUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LookupUserRequest.ProtoReflect.Descriptor instead.
+func (*LookupUserRequest) Descriptor() ([]byte, []int) {
+	return file_oauth_v1_auth_service_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *LookupUserRequest) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *LookupUserRequest) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+type LookupUserResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	User          *PublicUser            `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LookupUserResponse) Reset() {
+	*x = LookupUserResponse{}
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LookupUserResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LookupUserResponse) ProtoMessage() {}
+
+func (x *LookupUserResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LookupUserResponse.ProtoReflect.Descriptor instead.
+func (*LookupUserResponse) Descriptor() ([]byte, []int) {
+	return file_oauth_v1_auth_service_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *LookupUserResponse) GetUser() *PublicUser {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+type ListUsersRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Default 50, at most 200.
+	PageSize int32 `protobuf:"varint,1,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// next_page_token of the previous page, the filters must not change between pages.
+	PageToken string `protobuf:"bytes,2,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	// Matches username, email, first and last name. Can't be combined with username, email or email_domain.
+	Query string `protobuf:"bytes,3,opt,name=query,proto3" json:"query,omitempty"`
+	// Exact username.
+	Username string `protobuf:"bytes,4,opt,name=username,proto3" json:"username,omitempty"`
+	// Exact email.
+	Email string `protobuf:"bytes,5,opt,name=email,proto3" json:"email,omitempty"`
+	// Email domain, e.g. example.com. Can't be combined with username or email.
+	EmailDomain string `protobuf:"bytes,6,opt,name=email_domain,json=emailDomain,proto3" json:"email_domain,omitempty"`
+	Enabled     *bool  `protobuf:"varint,7,opt,name=enabled,proto3,oneof" json:"enabled,omitempty"`
+	// Group path, e.g. admin-group or parent/child. Can't be combined with other filters.
+	Group         string `protobuf:"bytes,8,opt,name=group,proto3" json:"group,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListUsersRequest) Reset() {
+	*x = ListUsersRequest{}
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListUsersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListUsersRequest) ProtoMessage() {}
+
+func (x *ListUsersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListUsersRequest.ProtoReflect.Descriptor instead.
+func (*ListUsersRequest) Descriptor() ([]byte, []int) {
+	return file_oauth_v1_auth_service_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ListUsersRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListUsersRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+func (x *ListUsersRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *ListUsersRequest) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *ListUsersRequest) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *ListUsersRequest) GetEmailDomain() string {
+	if x != nil {
+		return x.EmailDomain
+	}
+	return ""
+}
+
+func (x *ListUsersRequest) GetEnabled() bool {
+	if x != nil && x.Enabled != nil {
+		return *x.Enabled
+	}
+	return false
+}
+
+func (x *ListUsersRequest) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+type ListUsersResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Users []*PublicUser          `protobuf:"bytes,1,rep,name=users,proto3" json:"users,omitempty"`
+	// Empty on the last page.
+	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	// Total number of matching users, unset when unknown.
+	TotalSize     *int32 `protobuf:"varint,3,opt,name=total_size,json=totalSize,proto3,oneof" json:"total_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListUsersResponse) Reset() {
+	*x = ListUsersResponse{}
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListUsersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListUsersResponse) ProtoMessage() {}
+
+func (x *ListUsersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListUsersResponse.ProtoReflect.Descriptor instead.
+func (*ListUsersResponse) Descriptor() ([]byte, []int) {
+	return file_oauth_v1_auth_service_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ListUsersResponse) GetUsers() []*PublicUser {
+	if x != nil {
+		return x.Users
+	}
+	return nil
+}
+
+func (x *ListUsersResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+func (x *ListUsersResponse) GetTotalSize() int32 {
+	if x != nil && x.TotalSize != nil {
+		return *x.TotalSize
+	}
+	return 0
+}
+
+type BatchGetUsersRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// At most 500 IDs, duplicates are looked up once.
+	UserIds       []string `protobuf:"bytes,1,rep,name=user_ids,json=userIds,proto3" json:"user_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchGetUsersRequest) Reset() {
+	*x = BatchGetUsersRequest{}
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchGetUsersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGetUsersRequest) ProtoMessage() {}
+
+func (x *BatchGetUsersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGetUsersRequest.ProtoReflect.Descriptor instead.
+func (*BatchGetUsersRequest) Descriptor() ([]byte, []int) {
+	return file_oauth_v1_auth_service_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *BatchGetUsersRequest) GetUserIds() []string {
+	if x != nil {
+		return x.UserIds
+	}
+	return nil
+}
+
+type BatchGetUsersResult struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	UserId string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	// Unset when not_found is true.
+	User          *PublicUser `protobuf:"bytes,2,opt,name=user,proto3" json:"user,omitempty"`
+	NotFound      bool        `protobuf:"varint,3,opt,name=not_found,json=notFound,proto3" json:"not_found,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchGetUsersResult) Reset() {
+	*x = BatchGetUsersResult{}
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchGetUsersResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGetUsersResult) ProtoMessage() {}
+
+func (x *BatchGetUsersResult) ProtoReflect() protoreflect.Message {
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGetUsersResult.ProtoReflect.Descriptor instead.
+func (*BatchGetUsersResult) Descriptor() ([]byte, []int) {
+	return file_oauth_v1_auth_service_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *BatchGetUsersResult) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *BatchGetUsersResult) GetUser() *PublicUser {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+func (x *BatchGetUsersResult) GetNotFound() bool {
+	if x != nil {
+		return x.NotFound
+	}
+	return false
+}
+
+type BatchGetUsersResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// One result per requested ID, in request order.
+	Results       []*BatchGetUsersResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchGetUsersResponse) Reset() {
+	*x = BatchGetUsersResponse{}
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchGetUsersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGetUsersResponse) ProtoMessage() {}
+
+func (x *BatchGetUsersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGetUsersResponse.ProtoReflect.Descriptor instead.
+func (*BatchGetUsersResponse) Descriptor() ([]byte, []int) {
+	return file_oauth_v1_auth_service_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *BatchGetUsersResponse) GetResults() []*BatchGetUsersResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type GetMeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetMeRequest) Reset() {
+	*x = GetMeRequest{}
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetMeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetMeRequest) ProtoMessage() {}
+
+func (x *GetMeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetMeRequest.ProtoReflect.Descriptor instead.
+func (*GetMeRequest) Descriptor() ([]byte, []int) {
+	return file_oauth_v1_auth_service_proto_rawDescGZIP(), []int{10}
+}
+
+type ClientRoles struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Roles         []string               `protobuf:"bytes,1,rep,name=roles,proto3" json:"roles,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ClientRoles) Reset() {
+	*x = ClientRoles{}
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClientRoles) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClientRoles) ProtoMessage() {}
+
+func (x *ClientRoles) ProtoReflect() protoreflect.Message {
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClientRoles.ProtoReflect.Descriptor instead.
+func (*ClientRoles) Descriptor() ([]byte, []int) {
+	return file_oauth_v1_auth_service_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ClientRoles) GetRoles() []string {
+	if x != nil {
+		return x.Roles
+	}
+	return nil
+}
+
+type Group struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name  string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	// Full path, e.g. /parent/child.
+	Path          string `protobuf:"bytes,3,opt,name=path,proto3" json:"path,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Group) Reset() {
+	*x = Group{}
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Group) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Group) ProtoMessage() {}
+
+func (x *Group) ProtoReflect() protoreflect.Message {
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Group.ProtoReflect.Descriptor instead.
+func (*Group) Descriptor() ([]byte, []int) {
+	return file_oauth_v1_auth_service_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *Group) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Group) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Group) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+type Me struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	User  *PublicUser            `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	// Effective roles keyed by client ID, including roles granted through groups.
+	ClientRoles map[string]*ClientRoles `protobuf:"bytes,2,rep,name=client_roles,json=clientRoles,proto3" json:"client_roles,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// Groups the user is a direct member of.
+	Groups        []*Group `protobuf:"bytes,3,rep,name=groups,proto3" json:"groups,omitempty"`
+	EmailVerified bool     `protobuf:"varint,4,opt,name=email_verified,json=emailVerified,proto3" json:"email_verified,omitempty"`
+	// Actions the user must complete on next login, e.g. VERIFY_EMAIL.
+	RequiredActions []string               `protobuf:"bytes,5,rep,name=required_actions,json=requiredActions,proto3" json:"required_actions,omitempty"`
+	CreatedAt       *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Me) Reset() {
+	*x = Me{}
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Me) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Me) ProtoMessage() {}
+
+func (x *Me) ProtoReflect() protoreflect.Message {
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Me.ProtoReflect.Descriptor instead.
+func (*Me) Descriptor() ([]byte, []int) {
+	return file_oauth_v1_auth_service_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *Me) GetUser() *PublicUser {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+func (x *Me) GetClientRoles() map[string]*ClientRoles {
+	if x != nil {
+		return x.ClientRoles
+	}
+	return nil
+}
+
+func (x *Me) GetGroups() []*Group {
+	if x != nil {
+		return x.Groups
+	}
+	return nil
+}
+
+func (x *Me) GetEmailVerified() bool {
+	if x != nil {
+		return x.EmailVerified
+	}
+	return false
+}
+
+func (x *Me) GetRequiredActions() []string {
+	if x != nil {
+		return x.RequiredActions
+	}
+	return nil
+}
+
+func (x *Me) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type GetMeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Me            *Me                    `protobuf:"bytes,1,opt,name=me,proto3" json:"me,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetMeResponse) Reset() {
+	*x = GetMeResponse{}
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetMeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetMeResponse) ProtoMessage() {}
+
+func (x *GetMeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetMeResponse.ProtoReflect.Descriptor instead.
+func (*GetMeResponse) Descriptor() ([]byte, []int) {
+	return file_oauth_v1_auth_service_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *GetMeResponse) GetMe() *Me {
+	if x != nil {
+		return x.Me
+	}
+	return nil
+}
+
+type UpdateMeRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Only firstname, lastname and email can be updated.
+	User *PublicUser `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	// Fields of user to update. Set from the request body when omitted on PATCH /v1/me.
+	UpdateMask    *fieldmaskpb.FieldMask `protobuf:"bytes,2,opt,name=update_mask,json=updateMask,proto3" json:"update_mask,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateMeRequest) Reset() {
+	*x = UpdateMeRequest{}
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateMeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateMeRequest) ProtoMessage() {}
+
+func (x *UpdateMeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateMeRequest.ProtoReflect.Descriptor instead.
+func (*UpdateMeRequest) Descriptor() ([]byte, []int) {
+	return file_oauth_v1_auth_service_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *UpdateMeRequest) GetUser() *PublicUser {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+func (x *UpdateMeRequest) GetUpdateMask() *fieldmaskpb.FieldMask {
+	if x != nil {
+		return x.UpdateMask
+	}
+	return nil
+}
+
+type UpdateMeResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	User  *PublicUser            `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	// Whether a verification email was sent for a changed email.
+	VerificationEmailSent bool `protobuf:"varint,2,opt,name=verification_email_sent,json=verificationEmailSent,proto3" json:"verification_email_sent,omitempty"`
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
+}
+
+func (x *UpdateMeResponse) Reset() {
+	*x = UpdateMeResponse{}
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateMeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateMeResponse) ProtoMessage() {}
+
+func (x *UpdateMeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateMeResponse.ProtoReflect.Descriptor instead.
+func (*UpdateMeResponse) Descriptor() ([]byte, []int) {
+	return file_oauth_v1_auth_service_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *UpdateMeResponse) GetUser() *PublicUser {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+func (x *UpdateMeResponse) GetVerificationEmailSent() bool {
+	if x != nil {
+		return x.VerificationEmailSent
+	}
+	return false
+}
+
+type CreateUserRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Username      string                 `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	Email         string                 `protobuf:"bytes,2,opt,name=email,proto3" json:"email,omitempty"`
+	Firstname     string                 `protobuf:"bytes,3,opt,name=firstname,proto3" json:"firstname,omitempty"`
+	Lastname      string                 `protobuf:"bytes,4,opt,name=lastname,proto3" json:"lastname,omitempty"`
+	EmailVerified bool                   `protobuf:"varint,5,opt,name=email_verified,json=emailVerified,proto3" json:"email_verified,omitempty"`
+	// Group paths, e.g. default-group or parent/child.
+	Groups []string `protobuf:"bytes,6,rep,name=groups,proto3" json:"groups,omitempty"`
+	// Must be changed on first login. Without it the user has to reset their password.
+	TemporaryPassword string `protobuf:"bytes,7,opt,name=temporary_password,json=temporaryPassword,proto3" json:"temporary_password,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *CreateUserRequest) Reset() {
+	*x = CreateUserRequest{}
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateUserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateUserRequest) ProtoMessage() {}
+
+func (x *CreateUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateUserRequest.ProtoReflect.Descriptor instead.
+func (*CreateUserRequest) Descriptor() ([]byte, []int) {
+	return file_oauth_v1_auth_service_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *CreateUserRequest) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *CreateUserRequest) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *CreateUserRequest) GetFirstname() string {
+	if x != nil {
+		return x.Firstname
+	}
+	return ""
+}
+
+func (x *CreateUserRequest) GetLastname() string {
+	if x != nil {
+		return x.Lastname
+	}
+	return ""
+}
+
+func (x *CreateUserRequest) GetEmailVerified() bool {
+	if x != nil {
+		return x.EmailVerified
+	}
+	return false
+}
+
+func (x *CreateUserRequest) GetGroups() []string {
+	if x != nil {
+		return x.Groups
+	}
+	return nil
+}
+
+func (x *CreateUserRequest) GetTemporaryPassword() string {
+	if x != nil {
+		return x.TemporaryPassword
+	}
+	return ""
+}
+
+type CreateUserResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	User          *PublicUser            `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateUserResponse) Reset() {
+	*x = CreateUserResponse{}
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateUserResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateUserResponse) ProtoMessage() {}
+
+func (x *CreateUserResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateUserResponse.ProtoReflect.Descriptor instead.
+func (*CreateUserResponse) Descriptor() ([]byte, []int) {
+	return file_oauth_v1_auth_service_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *CreateUserResponse) GetUser() *PublicUser {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+type DisableUserRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Reason        string                 `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DisableUserRequest) Reset() {
+	*x = DisableUserRequest{}
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DisableUserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DisableUserRequest) ProtoMessage() {}
+
+func (x *DisableUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DisableUserRequest.ProtoReflect.Descriptor instead.
+func (*DisableUserRequest) Descriptor() ([]byte, []int) {
+	return file_oauth_v1_auth_service_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *DisableUserRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *DisableUserRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type DisableUserResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DisableUserResponse) Reset() {
+	*x = DisableUserResponse{}
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DisableUserResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DisableUserResponse) ProtoMessage() {}
+
+func (x *DisableUserResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DisableUserResponse.ProtoReflect.Descriptor instead.
+func (*DisableUserResponse) Descriptor() ([]byte, []int) {
+	return file_oauth_v1_auth_service_proto_rawDescGZIP(), []int{20}
+}
+
+type EnableUserRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EnableUserRequest) Reset() {
+	*x = EnableUserRequest{}
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EnableUserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EnableUserRequest) ProtoMessage() {}
+
+func (x *EnableUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EnableUserRequest.ProtoReflect.Descriptor instead.
+func (*EnableUserRequest) Descriptor() ([]byte, []int) {
+	return file_oauth_v1_auth_service_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *EnableUserRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type EnableUserResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EnableUserResponse) Reset() {
+	*x = EnableUserResponse{}
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EnableUserResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EnableUserResponse) ProtoMessage() {}
+
+func (x *EnableUserResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EnableUserResponse.ProtoReflect.Descriptor instead.
+func (*EnableUserResponse) Descriptor() ([]byte, []int) {
+	return file_oauth_v1_auth_service_proto_rawDescGZIP(), []int{22}
+}
+
+type DeleteUserRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteUserRequest) Reset() {
+	*x = DeleteUserRequest{}
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteUserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteUserRequest) ProtoMessage() {}
+
+func (x *DeleteUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteUserRequest.ProtoReflect.Descriptor instead.
+func (*DeleteUserRequest) Descriptor() ([]byte, []int) {
+	return file_oauth_v1_auth_service_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *DeleteUserRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type DeleteUserResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteUserResponse) Reset() {
+	*x = DeleteUserResponse{}
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteUserResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteUserResponse) ProtoMessage() {}
+
+func (x *DeleteUserResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteUserResponse.ProtoReflect.Descriptor instead.
+func (*DeleteUserResponse) Descriptor() ([]byte, []int) {
+	return file_oauth_v1_auth_service_proto_rawDescGZIP(), []int{24}
+}
+
+type ListGroupsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Default 50, at most 200 top-level groups.
+	PageSize  int32  `protobuf:"varint,1,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	PageToken string `protobuf:"bytes,2,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	// Matches group names.
+	Query         string `protobuf:"bytes,3,opt,name=query,proto3" json:"query,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListGroupsRequest) Reset() {
+	*x = ListGroupsRequest{}
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListGroupsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListGroupsRequest) ProtoMessage() {}
+
+func (x *ListGroupsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListGroupsRequest.ProtoReflect.Descriptor instead.
+func (*ListGroupsRequest) Descriptor() ([]byte, []int) {
+	return file_oauth_v1_auth_service_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *ListGroupsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListGroupsRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+func (x *ListGroupsRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+type ListGroupsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Top-level groups followed by their subgroups.
+	Groups        []*Group `protobuf:"bytes,1,rep,name=groups,proto3" json:"groups,omitempty"`
+	NextPageToken string   `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListGroupsResponse) Reset() {
+	*x = ListGroupsResponse{}
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListGroupsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListGroupsResponse) ProtoMessage() {}
+
+func (x *ListGroupsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListGroupsResponse.ProtoReflect.Descriptor instead.
+func (*ListGroupsResponse) Descriptor() ([]byte, []int) {
+	return file_oauth_v1_auth_service_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *ListGroupsResponse) GetGroups() []*Group {
+	if x != nil {
+		return x.Groups
+	}
+	return nil
+}
+
+func (x *ListGroupsResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+type ListGroupMembersRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Group path, e.g. admin-group or parent/child. Slashes of nested paths are escaped (%2F) in URLs.
+	Group string `protobuf:"bytes,1,opt,name=group,proto3" json:"group,omitempty"`
+	// Default 50, at most 200.
+	PageSize      int32  `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	PageToken     string `protobuf:"bytes,3,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListGroupMembersRequest) Reset() {
+	*x = ListGroupMembersRequest{}
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListGroupMembersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListGroupMembersRequest) ProtoMessage() {}
+
+func (x *ListGroupMembersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListGroupMembersRequest.ProtoReflect.Descriptor instead.
+func (*ListGroupMembersRequest) Descriptor() ([]byte, []int) {
+	return file_oauth_v1_auth_service_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *ListGroupMembersRequest) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+func (x *ListGroupMembersRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListGroupMembersRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type ListGroupMembersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Users         []*PublicUser          `protobuf:"bytes,1,rep,name=users,proto3" json:"users,omitempty"`
+	NextPageToken string                 `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListGroupMembersResponse) Reset() {
+	*x = ListGroupMembersResponse{}
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListGroupMembersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListGroupMembersResponse) ProtoMessage() {}
+
+func (x *ListGroupMembersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListGroupMembersResponse.ProtoReflect.Descriptor instead.
+func (*ListGroupMembersResponse) Descriptor() ([]byte, []int) {
+	return file_oauth_v1_auth_service_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *ListGroupMembersResponse) GetUsers() []*PublicUser {
+	if x != nil {
+		return x.Users
+	}
+	return nil
+}
+
+func (x *ListGroupMembersResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+type AddUserToGroupRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Group path, e.g. admin-group.
+	Group         string `protobuf:"bytes,1,opt,name=group,proto3" json:"group,omitempty"`
+	UserId        string `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddUserToGroupRequest) Reset() {
+	*x = AddUserToGroupRequest{}
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddUserToGroupRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddUserToGroupRequest) ProtoMessage() {}
+
+func (x *AddUserToGroupRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddUserToGroupRequest.ProtoReflect.Descriptor instead.
+func (*AddUserToGroupRequest) Descriptor() ([]byte, []int) {
+	return file_oauth_v1_auth_service_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *AddUserToGroupRequest) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+func (x *AddUserToGroupRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type AddUserToGroupResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddUserToGroupResponse) Reset() {
+	*x = AddUserToGroupResponse{}
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddUserToGroupResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddUserToGroupResponse) ProtoMessage() {}
+
+func (x *AddUserToGroupResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddUserToGroupResponse.ProtoReflect.Descriptor instead.
+func (*AddUserToGroupResponse) Descriptor() ([]byte, []int) {
+	return file_oauth_v1_auth_service_proto_rawDescGZIP(), []int{30}
+}
+
+type RemoveUserFromGroupRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Group path, e.g. admin-group.
+	Group         string `protobuf:"bytes,1,opt,name=group,proto3" json:"group,omitempty"`
+	UserId        string `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoveUserFromGroupRequest) Reset() {
+	*x = RemoveUserFromGroupRequest{}
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveUserFromGroupRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveUserFromGroupRequest) ProtoMessage() {}
+
+func (x *RemoveUserFromGroupRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveUserFromGroupRequest.ProtoReflect.Descriptor instead.
+func (*RemoveUserFromGroupRequest) Descriptor() ([]byte, []int) {
+	return file_oauth_v1_auth_service_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *RemoveUserFromGroupRequest) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+func (x *RemoveUserFromGroupRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type RemoveUserFromGroupResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoveUserFromGroupResponse) Reset() {
+	*x = RemoveUserFromGroupResponse{}
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveUserFromGroupResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveUserFromGroupResponse) ProtoMessage() {}
+
+func (x *RemoveUserFromGroupResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveUserFromGroupResponse.ProtoReflect.Descriptor instead.
+func (*RemoveUserFromGroupResponse) Descriptor() ([]byte, []int) {
+	return file_oauth_v1_auth_service_proto_rawDescGZIP(), []int{32}
+}
+
+type SetUserTierRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	UserId string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	// One of the tiers configured in USER_TIER_GROUPS, e.g. default or pro.
+	Tier string `protobuf:"bytes,2,opt,name=tier,proto3" json:"tier,omitempty"`
+	// When the tier lapses, unset for no expiry. Must be unset for the default tier.
+	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetUserTierRequest) Reset() {
+	*x = SetUserTierRequest{}
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetUserTierRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetUserTierRequest) ProtoMessage() {}
+
+func (x *SetUserTierRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetUserTierRequest.ProtoReflect.Descriptor instead.
+func (*SetUserTierRequest) Descriptor() ([]byte, []int) {
+	return file_oauth_v1_auth_service_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *SetUserTierRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *SetUserTierRequest) GetTier() string {
+	if x != nil {
+		return x.Tier
+	}
+	return ""
+}
+
+func (x *SetUserTierRequest) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+type SetUserTierResponse struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Tier      string                 `protobuf:"bytes,1,opt,name=tier,proto3" json:"tier,omitempty"`
+	ExpiresAt *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	// Whether group memberships changed, false when the user already was in the tier.
+	Changed bool `protobuf:"varint,3,opt,name=changed,proto3" json:"changed,omitempty"`
+	// Effective roles after the change keyed by client ID.
+	ClientRoles   map[string]*ClientRoles `protobuf:"bytes,4,rep,name=client_roles,json=clientRoles,proto3" json:"client_roles,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetUserTierResponse) Reset() {
+	*x = SetUserTierResponse{}
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetUserTierResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetUserTierResponse) ProtoMessage() {}
+
+func (x *SetUserTierResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetUserTierResponse.ProtoReflect.Descriptor instead.
+func (*SetUserTierResponse) Descriptor() ([]byte, []int) {
+	return file_oauth_v1_auth_service_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *SetUserTierResponse) GetTier() string {
+	if x != nil {
+		return x.Tier
+	}
+	return ""
+}
+
+func (x *SetUserTierResponse) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+func (x *SetUserTierResponse) GetChanged() bool {
+	if x != nil {
+		return x.Changed
+	}
+	return false
+}
+
+func (x *SetUserTierResponse) GetClientRoles() map[string]*ClientRoles {
+	if x != nil {
+		return x.ClientRoles
+	}
+	return nil
+}
+
+type InvalidateUserCacheRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// User to evict, ignored when all is set.
+	UserId string `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	// Flush the whole cache.
+	All           bool `protobuf:"varint,2,opt,name=all,proto3" json:"all,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InvalidateUserCacheRequest) Reset() {
+	*x = InvalidateUserCacheRequest{}
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InvalidateUserCacheRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InvalidateUserCacheRequest) ProtoMessage() {}
+
+func (x *InvalidateUserCacheRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InvalidateUserCacheRequest.ProtoReflect.Descriptor instead.
+func (*InvalidateUserCacheRequest) Descriptor() ([]byte, []int) {
+	return file_oauth_v1_auth_service_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *InvalidateUserCacheRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *InvalidateUserCacheRequest) GetAll() bool {
+	if x != nil {
+		return x.All
+	}
+	return false
+}
+
+type UserCacheStats struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Hits          uint64                 `protobuf:"varint,1,opt,name=hits,proto3" json:"hits,omitempty"`
+	Misses        uint64                 `protobuf:"varint,2,opt,name=misses,proto3" json:"misses,omitempty"`
+	Size          int64                  `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UserCacheStats) Reset() {
+	*x = UserCacheStats{}
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UserCacheStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UserCacheStats) ProtoMessage() {}
+
+func (x *UserCacheStats) ProtoReflect() protoreflect.Message {
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UserCacheStats.ProtoReflect.Descriptor instead.
+func (*UserCacheStats) Descriptor() ([]byte, []int) {
+	return file_oauth_v1_auth_service_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *UserCacheStats) GetHits() uint64 {
+	if x != nil {
+		return x.Hits
+	}
+	return 0
+}
+
+func (x *UserCacheStats) GetMisses() uint64 {
+	if x != nil {
+		return x.Misses
+	}
+	return 0
+}
+
+func (x *UserCacheStats) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+type InvalidateUserCacheResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Cache counters after the eviction.
+	Stats         *UserCacheStats `protobuf:"bytes,1,opt,name=stats,proto3" json:"stats,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InvalidateUserCacheResponse) Reset() {
+	*x = InvalidateUserCacheResponse{}
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InvalidateUserCacheResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InvalidateUserCacheResponse) ProtoMessage() {}
+
+func (x *InvalidateUserCacheResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InvalidateUserCacheResponse.ProtoReflect.Descriptor instead.
+func (*InvalidateUserCacheResponse) Descriptor() ([]byte, []int) {
+	return file_oauth_v1_auth_service_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *InvalidateUserCacheResponse) GetStats() *UserCacheStats {
+	if x != nil {
+		return x.Stats
+	}
+	return nil
+}
+
+// Tokens issued by Keycloak
+type Token struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	AccessToken  string                 `protobuf:"bytes,1,opt,name=access_token,json=accessToken,proto3" json:"access_token,omitempty"`
+	RefreshToken string                 `protobuf:"bytes,2,opt,name=refresh_token,json=refreshToken,proto3" json:"refresh_token,omitempty"`
+	// Only set when the openid scope was granted.
+	IdToken string `protobuf:"bytes,3,opt,name=id_token,json=idToken,proto3" json:"id_token,omitempty"`
+	// Always "Bearer".
+	TokenType string `protobuf:"bytes,4,opt,name=token_type,json=tokenType,proto3" json:"token_type,omitempty"`
+	// Lifetime of the access token in seconds.
+	ExpiresIn int32 `protobuf:"varint,5,opt,name=expires_in,json=expiresIn,proto3" json:"expires_in,omitempty"`
+	// Lifetime of the refresh token in seconds, 0 for offline tokens.
+	RefreshExpiresIn int32 `protobuf:"varint,6,opt,name=refresh_expires_in,json=refreshExpiresIn,proto3" json:"refresh_expires_in,omitempty"`
+	// Space separated scopes granted.
+	Scope string `protobuf:"bytes,7,opt,name=scope,proto3" json:"scope,omitempty"`
+	// Keycloak session the tokens belong to.
+	SessionId     string `protobuf:"bytes,8,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Token) Reset() {
+	*x = Token{}
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Token) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Token) ProtoMessage() {}
+
+func (x *Token) ProtoReflect() protoreflect.Message {
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Token.ProtoReflect.Descriptor instead.
+func (*Token) Descriptor() ([]byte, []int) {
+	return file_oauth_v1_auth_service_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *Token) GetAccessToken() string {
+	if x != nil {
+		return x.AccessToken
+	}
+	return ""
+}
+
+func (x *Token) GetRefreshToken() string {
+	if x != nil {
+		return x.RefreshToken
+	}
+	return ""
+}
+
+func (x *Token) GetIdToken() string {
+	if x != nil {
+		return x.IdToken
+	}
+	return ""
+}
+
+func (x *Token) GetTokenType() string {
+	if x != nil {
+		return x.TokenType
+	}
+	return ""
+}
+
+func (x *Token) GetExpiresIn() int32 {
+	if x != nil {
+		return x.ExpiresIn
+	}
+	return 0
+}
+
+func (x *Token) GetRefreshExpiresIn() int32 {
+	if x != nil {
+		return x.RefreshExpiresIn
+	}
+	return 0
+}
+
+func (x *Token) GetScope() string {
+	if x != nil {
+		return x.Scope
+	}
+	return ""
+}
+
+func (x *Token) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+type LoginRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Username      string                 `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	Password      string                 `protobuf:"bytes,2,opt,name=password,proto3" json:"password,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LoginRequest) Reset() {
+	*x = LoginRequest{}
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LoginRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LoginRequest) ProtoMessage() {}
+
+func (x *LoginRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LoginRequest.ProtoReflect.Descriptor instead.
+func (*LoginRequest) Descriptor() ([]byte, []int) {
+	return file_oauth_v1_auth_service_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *LoginRequest) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *LoginRequest) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+type LoginResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Token         *Token                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LoginResponse) Reset() {
+	*x = LoginResponse{}
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LoginResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LoginResponse) ProtoMessage() {}
+
+func (x *LoginResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LoginResponse.ProtoReflect.Descriptor instead.
+func (*LoginResponse) Descriptor() ([]byte, []int) {
+	return file_oauth_v1_auth_service_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *LoginResponse) GetToken() *Token {
+	if x != nil {
+		return x.Token
+	}
+	return nil
+}
+
+type RefreshTokenRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RefreshToken  string                 `protobuf:"bytes,1,opt,name=refresh_token,json=refreshToken,proto3" json:"refresh_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RefreshTokenRequest) Reset() {
+	*x = RefreshTokenRequest{}
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RefreshTokenRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RefreshTokenRequest) ProtoMessage() {}
+
+func (x *RefreshTokenRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RefreshTokenRequest.ProtoReflect.Descriptor instead.
+func (*RefreshTokenRequest) Descriptor() ([]byte, []int) {
+	return file_oauth_v1_auth_service_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *RefreshTokenRequest) GetRefreshToken() string {
+	if x != nil {
+		return x.RefreshToken
+	}
+	return ""
+}
+
+type RefreshTokenResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Token         *Token                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RefreshTokenResponse) Reset() {
+	*x = RefreshTokenResponse{}
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RefreshTokenResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RefreshTokenResponse) ProtoMessage() {}
+
+func (x *RefreshTokenResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RefreshTokenResponse.ProtoReflect.Descriptor instead.
+func (*RefreshTokenResponse) Descriptor() ([]byte, []int) {
+	return file_oauth_v1_auth_service_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *RefreshTokenResponse) GetToken() *Token {
+	if x != nil {
+		return x.Token
+	}
+	return nil
+}
+
+type LogoutRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RefreshToken  string                 `protobuf:"bytes,1,opt,name=refresh_token,json=refreshToken,proto3" json:"refresh_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LogoutRequest) Reset() {
+	*x = LogoutRequest{}
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LogoutRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogoutRequest) ProtoMessage() {}
+
+func (x *LogoutRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogoutRequest.ProtoReflect.Descriptor instead.
+func (*LogoutRequest) Descriptor() ([]byte, []int) {
+	return file_oauth_v1_auth_service_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *LogoutRequest) GetRefreshToken() string {
+	if x != nil {
+		return x.RefreshToken
+	}
+	return ""
+}
+
+type LogoutResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LogoutResponse) Reset() {
+	*x = LogoutResponse{}
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LogoutResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogoutResponse) ProtoMessage() {}
+
+func (x *LogoutResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogoutResponse.ProtoReflect.Descriptor instead.
+func (*LogoutResponse) Descriptor() ([]byte, []int) {
+	return file_oauth_v1_auth_service_proto_rawDescGZIP(), []int{44}
+}
+
+// An active Keycloak session
+type UserSession struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// IP address the session was started from.
+	IpAddress      string                 `protobuf:"bytes,2,opt,name=ip_address,json=ipAddress,proto3" json:"ip_address,omitempty"`
+	StartTime      *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	LastAccessTime *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=last_access_time,json=lastAccessTime,proto3" json:"last_access_time,omitempty"`
+	// Client IDs the session signed in to.
+	Clients []string `protobuf:"bytes,5,rep,name=clients,proto3" json:"clients,omitempty"`
+	// Whether the session issued the caller's token.
+	Current       bool `protobuf:"varint,6,opt,name=current,proto3" json:"current,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UserSession) Reset() {
+	*x = UserSession{}
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UserSession) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UserSession) ProtoMessage() {}
+
+func (x *UserSession) ProtoReflect() protoreflect.Message {
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UserSession.ProtoReflect.Descriptor instead.
+func (*UserSession) Descriptor() ([]byte, []int) {
+	return file_oauth_v1_auth_service_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *UserSession) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *UserSession) GetIpAddress() string {
+	if x != nil {
+		return x.IpAddress
+	}
+	return ""
+}
+
+func (x *UserSession) GetStartTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartTime
+	}
+	return nil
+}
+
+func (x *UserSession) GetLastAccessTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastAccessTime
+	}
+	return nil
+}
+
+func (x *UserSession) GetClients() []string {
+	if x != nil {
+		return x.Clients
+	}
+	return nil
+}
+
+func (x *UserSession) GetCurrent() bool {
+	if x != nil {
+		return x.Current
+	}
+	return false
+}
+
+type ListMySessionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListMySessionsRequest) Reset() {
+	*x = ListMySessionsRequest{}
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListMySessionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListMySessionsRequest) ProtoMessage() {}
+
+func (x *ListMySessionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListMySessionsRequest.ProtoReflect.Descriptor instead.
+func (*ListMySessionsRequest) Descriptor() ([]byte, []int) {
+	return file_oauth_v1_auth_service_proto_rawDescGZIP(), []int{46}
+}
+
+type ListMySessionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sessions      []*UserSession         `protobuf:"bytes,1,rep,name=sessions,proto3" json:"sessions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListMySessionsResponse) Reset() {
+	*x = ListMySessionsResponse{}
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListMySessionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListMySessionsResponse) ProtoMessage() {}
+
+func (x *ListMySessionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListMySessionsResponse.ProtoReflect.Descriptor instead.
+func (*ListMySessionsResponse) Descriptor() ([]byte, []int) {
+	return file_oauth_v1_auth_service_proto_rawDescGZIP(), []int{47}
+}
+
+func (x *ListMySessionsResponse) GetSessions() []*UserSession {
+	if x != nil {
+		return x.Sessions
+	}
+	return nil
+}
+
+type RevokeMySessionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeMySessionRequest) Reset() {
+	*x = RevokeMySessionRequest{}
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeMySessionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeMySessionRequest) ProtoMessage() {}
+
+func (x *RevokeMySessionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeMySessionRequest.ProtoReflect.Descriptor instead.
+func (*RevokeMySessionRequest) Descriptor() ([]byte, []int) {
+	return file_oauth_v1_auth_service_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *RevokeMySessionRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+type RevokeMySessionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeMySessionResponse) Reset() {
+	*x = RevokeMySessionResponse{}
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeMySessionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeMySessionResponse) ProtoMessage() {}
+
+func (x *RevokeMySessionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[49]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeMySessionResponse.ProtoReflect.Descriptor instead.
+func (*RevokeMySessionResponse) Descriptor() ([]byte, []int) {
+	return file_oauth_v1_auth_service_proto_rawDescGZIP(), []int{49}
+}
+
+type ListUserSessionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListUserSessionsRequest) Reset() {
+	*x = ListUserSessionsRequest{}
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[50]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListUserSessionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListUserSessionsRequest) ProtoMessage() {}
+
+func (x *ListUserSessionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[50]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListUserSessionsRequest.ProtoReflect.Descriptor instead.
+func (*ListUserSessionsRequest) Descriptor() ([]byte, []int) {
+	return file_oauth_v1_auth_service_proto_rawDescGZIP(), []int{50}
+}
+
+func (x *ListUserSessionsRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type ListUserSessionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sessions      []*UserSession         `protobuf:"bytes,1,rep,name=sessions,proto3" json:"sessions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListUserSessionsResponse) Reset() {
+	*x = ListUserSessionsResponse{}
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[51]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListUserSessionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListUserSessionsResponse) ProtoMessage() {}
+
+func (x *ListUserSessionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[51]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListUserSessionsResponse.ProtoReflect.Descriptor instead.
+func (*ListUserSessionsResponse) Descriptor() ([]byte, []int) {
+	return file_oauth_v1_auth_service_proto_rawDescGZIP(), []int{51}
+}
+
+func (x *ListUserSessionsResponse) GetSessions() []*UserSession {
+	if x != nil {
+		return x.Sessions
+	}
+	return nil
+}
+
+type RevokeAllUserSessionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeAllUserSessionsRequest) Reset() {
+	*x = RevokeAllUserSessionsRequest{}
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[52]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeAllUserSessionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeAllUserSessionsRequest) ProtoMessage() {}
+
+func (x *RevokeAllUserSessionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[52]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeAllUserSessionsRequest.ProtoReflect.Descriptor instead.
+func (*RevokeAllUserSessionsRequest) Descriptor() ([]byte, []int) {
+	return file_oauth_v1_auth_service_proto_rawDescGZIP(), []int{52}
+}
+
+func (x *RevokeAllUserSessionsRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type RevokeAllUserSessionsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Number of sessions ended.
+	Revoked       int32 `protobuf:"varint,1,opt,name=revoked,proto3" json:"revoked,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeAllUserSessionsResponse) Reset() {
+	*x = RevokeAllUserSessionsResponse{}
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[53]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeAllUserSessionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeAllUserSessionsResponse) ProtoMessage() {}
+
+func (x *RevokeAllUserSessionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_oauth_v1_auth_service_proto_msgTypes[53]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeAllUserSessionsResponse.ProtoReflect.Descriptor instead.
+func (*RevokeAllUserSessionsResponse) Descriptor() ([]byte, []int) {
+	return file_oauth_v1_auth_service_proto_rawDescGZIP(), []int{53}
+}
+
+func (x *RevokeAllUserSessionsResponse) GetRevoked() int32 {
+	if x != nil {
+		return x.Revoked
+	}
+	return 0
+}
+
 var File_oauth_v1_auth_service_proto protoreflect.FileDescriptor
 
 const file_oauth_v1_auth_service_proto_rawDesc = "" +
 	"\n" +
-	"\x1boauth/v1/auth_service.proto\x12\boauth.v1\x1a\x1cgoogle/api/annotations.proto\x1a.protoc-gen-openapiv2/options/annotations.proto\"\x88\x01\n" +
+	"\x1boauth/v1/auth_service.proto\x12\boauth.v1\x1a\x1cgoogle/api/annotations.proto\x1a google/protobuf/field_mask.proto\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x16oauth/v1/options.proto\x1a.protoc-gen-openapiv2/options/annotations.proto\"\x88\x01\n" +
 	"\n" +
 	"PublicUser\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1a\n" +
@@ -202,9 +2880,226 @@ const file_oauth_v1_auth_service_proto_rawDesc = "" +
 	"\x0eGetUserRequest\x12\x17\n" +
 	"\auser_id\x18\x01 \x01(\tR\x06userId\";\n" +
 	"\x0fGetUserResponse\x12(\n" +
-	"\x04user\x18\x01 \x01(\v2\x14.oauth.v1.PublicUserR\x04user2j\n" +
-	"\vAuthService\x12[\n" +
-	"\aGetUser\x12\x18.oauth.v1.GetUserRequest\x1a\x19.oauth.v1.GetUserResponse\"\x1b\x82\xd3\xe4\x93\x02\x15\x12\x13/v1/users/{user_id}B\xfc\x01\x92A\xc7\x01\x12\x9d\x01\n" +
+	"\x04user\x18\x01 \x01(\v2\x14.oauth.v1.PublicUserR\x04user\"E\n" +
+	"\x11LookupUserRequest\x12\x1a\n" +
+	"\busername\x18\x01 \x01(\tR\busername\x12\x14\n" +
+	"\x05email\x18\x02 \x01(\tR\x05email\">\n" +
+	"\x12LookupUserResponse\x12(\n" +
+	"\x04user\x18\x01 \x01(\v2\x14.oauth.v1.PublicUserR\x04user\"\xfa\x01\n" +
+	"\x10ListUsersRequest\x12\x1b\n" +
+	"\tpage_size\x18\x01 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x02 \x01(\tR\tpageToken\x12\x14\n" +
+	"\x05query\x18\x03 \x01(\tR\x05query\x12\x1a\n" +
+	"\busername\x18\x04 \x01(\tR\busername\x12\x14\n" +
+	"\x05email\x18\x05 \x01(\tR\x05email\x12!\n" +
+	"\femail_domain\x18\x06 \x01(\tR\vemailDomain\x12\x1d\n" +
+	"\aenabled\x18\a \x01(\bH\x00R\aenabled\x88\x01\x01\x12\x14\n" +
+	"\x05group\x18\b \x01(\tR\x05groupB\n" +
+	"\n" +
+	"\b_enabled\"\x9a\x01\n" +
+	"\x11ListUsersResponse\x12*\n" +
+	"\x05users\x18\x01 \x03(\v2\x14.oauth.v1.PublicUserR\x05users\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\x12\"\n" +
+	"\n" +
+	"total_size\x18\x03 \x01(\x05H\x00R\ttotalSize\x88\x01\x01B\r\n" +
+	"\v_total_size\"1\n" +
+	"\x14BatchGetUsersRequest\x12\x19\n" +
+	"\buser_ids\x18\x01 \x03(\tR\auserIds\"u\n" +
+	"\x13BatchGetUsersResult\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12(\n" +
+	"\x04user\x18\x02 \x01(\v2\x14.oauth.v1.PublicUserR\x04user\x12\x1b\n" +
+	"\tnot_found\x18\x03 \x01(\bR\bnotFound\"P\n" +
+	"\x15BatchGetUsersResponse\x127\n" +
+	"\aresults\x18\x01 \x03(\v2\x1d.oauth.v1.BatchGetUsersResultR\aresults\"\x0e\n" +
+	"\fGetMeRequest\"#\n" +
+	"\vClientRoles\x12\x14\n" +
+	"\x05roles\x18\x01 \x03(\tR\x05roles\"?\n" +
+	"\x05Group\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x12\n" +
+	"\x04path\x18\x03 \x01(\tR\x04path\"\xfd\x02\n" +
+	"\x02Me\x12(\n" +
+	"\x04user\x18\x01 \x01(\v2\x14.oauth.v1.PublicUserR\x04user\x12@\n" +
+	"\fclient_roles\x18\x02 \x03(\v2\x1d.oauth.v1.Me.ClientRolesEntryR\vclientRoles\x12'\n" +
+	"\x06groups\x18\x03 \x03(\v2\x0f.oauth.v1.GroupR\x06groups\x12%\n" +
+	"\x0eemail_verified\x18\x04 \x01(\bR\remailVerified\x12)\n" +
+	"\x10required_actions\x18\x05 \x03(\tR\x0frequiredActions\x129\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x1aU\n" +
+	"\x10ClientRolesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12+\n" +
+	"\x05value\x18\x02 \x01(\v2\x15.oauth.v1.ClientRolesR\x05value:\x028\x01\"-\n" +
+	"\rGetMeResponse\x12\x1c\n" +
+	"\x02me\x18\x01 \x01(\v2\f.oauth.v1.MeR\x02me\"x\n" +
+	"\x0fUpdateMeRequest\x12(\n" +
+	"\x04user\x18\x01 \x01(\v2\x14.oauth.v1.PublicUserR\x04user\x12;\n" +
+	"\vupdate_mask\x18\x02 \x01(\v2\x1a.google.protobuf.FieldMaskR\n" +
+	"updateMask\"t\n" +
+	"\x10UpdateMeResponse\x12(\n" +
+	"\x04user\x18\x01 \x01(\v2\x14.oauth.v1.PublicUserR\x04user\x126\n" +
+	"\x17verification_email_sent\x18\x02 \x01(\bR\x15verificationEmailSent\"\xed\x01\n" +
+	"\x11CreateUserRequest\x12\x1a\n" +
+	"\busername\x18\x01 \x01(\tR\busername\x12\x14\n" +
+	"\x05email\x18\x02 \x01(\tR\x05email\x12\x1c\n" +
+	"\tfirstname\x18\x03 \x01(\tR\tfirstname\x12\x1a\n" +
+	"\blastname\x18\x04 \x01(\tR\blastname\x12%\n" +
+	"\x0eemail_verified\x18\x05 \x01(\bR\remailVerified\x12\x16\n" +
+	"\x06groups\x18\x06 \x03(\tR\x06groups\x12-\n" +
+	"\x12temporary_password\x18\a \x01(\tR\x11temporaryPassword\">\n" +
+	"\x12CreateUserResponse\x12(\n" +
+	"\x04user\x18\x01 \x01(\v2\x14.oauth.v1.PublicUserR\x04user\"E\n" +
+	"\x12DisableUserRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\"\x15\n" +
+	"\x13DisableUserResponse\",\n" +
+	"\x11EnableUserRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\"\x14\n" +
+	"\x12EnableUserResponse\",\n" +
+	"\x11DeleteUserRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\"\x14\n" +
+	"\x12DeleteUserResponse\"e\n" +
+	"\x11ListGroupsRequest\x12\x1b\n" +
+	"\tpage_size\x18\x01 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x02 \x01(\tR\tpageToken\x12\x14\n" +
+	"\x05query\x18\x03 \x01(\tR\x05query\"e\n" +
+	"\x12ListGroupsResponse\x12'\n" +
+	"\x06groups\x18\x01 \x03(\v2\x0f.oauth.v1.GroupR\x06groups\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"k\n" +
+	"\x17ListGroupMembersRequest\x12\x14\n" +
+	"\x05group\x18\x01 \x01(\tR\x05group\x12\x1b\n" +
+	"\tpage_size\x18\x02 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x03 \x01(\tR\tpageToken\"n\n" +
+	"\x18ListGroupMembersResponse\x12*\n" +
+	"\x05users\x18\x01 \x03(\v2\x14.oauth.v1.PublicUserR\x05users\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"F\n" +
+	"\x15AddUserToGroupRequest\x12\x14\n" +
+	"\x05group\x18\x01 \x01(\tR\x05group\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\"\x18\n" +
+	"\x16AddUserToGroupResponse\"K\n" +
+	"\x1aRemoveUserFromGroupRequest\x12\x14\n" +
+	"\x05group\x18\x01 \x01(\tR\x05group\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\"\x1d\n" +
+	"\x1bRemoveUserFromGroupResponse\"|\n" +
+	"\x12SetUserTierRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x12\n" +
+	"\x04tier\x18\x02 \x01(\tR\x04tier\x129\n" +
+	"\n" +
+	"expires_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\"\xa8\x02\n" +
+	"\x13SetUserTierResponse\x12\x12\n" +
+	"\x04tier\x18\x01 \x01(\tR\x04tier\x129\n" +
+	"\n" +
+	"expires_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\x12\x18\n" +
+	"\achanged\x18\x03 \x01(\bR\achanged\x12Q\n" +
+	"\fclient_roles\x18\x04 \x03(\v2..oauth.v1.SetUserTierResponse.ClientRolesEntryR\vclientRoles\x1aU\n" +
+	"\x10ClientRolesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12+\n" +
+	"\x05value\x18\x02 \x01(\v2\x15.oauth.v1.ClientRolesR\x05value:\x028\x01\"G\n" +
+	"\x1aInvalidateUserCacheRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x10\n" +
+	"\x03all\x18\x02 \x01(\bR\x03all\"P\n" +
+	"\x0eUserCacheStats\x12\x12\n" +
+	"\x04hits\x18\x01 \x01(\x04R\x04hits\x12\x16\n" +
+	"\x06misses\x18\x02 \x01(\x04R\x06misses\x12\x12\n" +
+	"\x04size\x18\x03 \x01(\x03R\x04size\"M\n" +
+	"\x1bInvalidateUserCacheResponse\x12.\n" +
+	"\x05stats\x18\x01 \x01(\v2\x18.oauth.v1.UserCacheStatsR\x05stats\"\x8b\x02\n" +
+	"\x05Token\x12!\n" +
+	"\faccess_token\x18\x01 \x01(\tR\vaccessToken\x12#\n" +
+	"\rrefresh_token\x18\x02 \x01(\tR\frefreshToken\x12\x19\n" +
+	"\bid_token\x18\x03 \x01(\tR\aidToken\x12\x1d\n" +
+	"\n" +
+	"token_type\x18\x04 \x01(\tR\ttokenType\x12\x1d\n" +
+	"\n" +
+	"expires_in\x18\x05 \x01(\x05R\texpiresIn\x12,\n" +
+	"\x12refresh_expires_in\x18\x06 \x01(\x05R\x10refreshExpiresIn\x12\x14\n" +
+	"\x05scope\x18\a \x01(\tR\x05scope\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\b \x01(\tR\tsessionId\"F\n" +
+	"\fLoginRequest\x12\x1a\n" +
+	"\busername\x18\x01 \x01(\tR\busername\x12\x1a\n" +
+	"\bpassword\x18\x02 \x01(\tR\bpassword\"6\n" +
+	"\rLoginResponse\x12%\n" +
+	"\x05token\x18\x01 \x01(\v2\x0f.oauth.v1.TokenR\x05token\":\n" +
+	"\x13RefreshTokenRequest\x12#\n" +
+	"\rrefresh_token\x18\x01 \x01(\tR\frefreshToken\"=\n" +
+	"\x14RefreshTokenResponse\x12%\n" +
+	"\x05token\x18\x01 \x01(\v2\x0f.oauth.v1.TokenR\x05token\"4\n" +
+	"\rLogoutRequest\x12#\n" +
+	"\rrefresh_token\x18\x01 \x01(\tR\frefreshToken\"\x10\n" +
+	"\x0eLogoutResponse\"\xf1\x01\n" +
+	"\vUserSession\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1d\n" +
+	"\n" +
+	"ip_address\x18\x02 \x01(\tR\tipAddress\x129\n" +
+	"\n" +
+	"start_time\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tstartTime\x12D\n" +
+	"\x10last_access_time\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\x0elastAccessTime\x12\x18\n" +
+	"\aclients\x18\x05 \x03(\tR\aclients\x12\x18\n" +
+	"\acurrent\x18\x06 \x01(\bR\acurrent\"\x17\n" +
+	"\x15ListMySessionsRequest\"K\n" +
+	"\x16ListMySessionsResponse\x121\n" +
+	"\bsessions\x18\x01 \x03(\v2\x15.oauth.v1.UserSessionR\bsessions\"7\n" +
+	"\x16RevokeMySessionRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\"\x19\n" +
+	"\x17RevokeMySessionResponse\"2\n" +
+	"\x17ListUserSessionsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\"M\n" +
+	"\x18ListUserSessionsResponse\x121\n" +
+	"\bsessions\x18\x01 \x03(\v2\x15.oauth.v1.UserSessionR\bsessions\"7\n" +
+	"\x1cRevokeAllUserSessionsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\"9\n" +
+	"\x1dRevokeAllUserSessionsResponse\x12\x18\n" +
+	"\arevoked\x18\x01 \x01(\x05R\arevoked2\xcb\x15\n" +
+	"\vAuthService\x12e\n" +
+	"\aGetUser\x12\x18.oauth.v1.GetUserRequest\x1a\x19.oauth.v1.GetUserResponse\"%\x8a\xb5\x18\x06\n" +
+	"\x04user\x82\xd3\xe4\x93\x02\x15\x12\x13/v1/users/{user_id}\x12k\n" +
+	"\n" +
+	"LookupUser\x12\x1b.oauth.v1.LookupUserRequest\x1a\x1c.oauth.v1.LookupUserResponse\"\"\x8a\xb5\x18\x06\n" +
+	"\x04user\x82\xd3\xe4\x93\x02\x12\x12\x10/v1/users:lookup\x12b\n" +
+	"\tListUsers\x12\x1a.oauth.v1.ListUsersRequest\x1a\x1b.oauth.v1.ListUsersResponse\"\x1c\x8a\xb5\x18\a\n" +
+	"\x05admin\x82\xd3\xe4\x93\x02\v\x12\t/v1/users\x12[\n" +
+	"\bUpdateMe\x12\x19.oauth.v1.UpdateMeRequest\x1a\x1a.oauth.v1.UpdateMeResponse\"\x18\x8a\xb5\x18\x00\x82\xd3\xe4\x93\x02\x0e:\x04user2\x06/v1/me\x12y\n" +
+	"\rBatchGetUsers\x12\x1e.oauth.v1.BatchGetUsersRequest\x1a\x1f.oauth.v1.BatchGetUsersResponse\"'\x8a\xb5\x18\x06\n" +
+	"\x04user\x82\xd3\xe4\x93\x02\x17:\x01*\"\x12/v1/users:batchGet\x12L\n" +
+	"\x05GetMe\x12\x16.oauth.v1.GetMeRequest\x1a\x17.oauth.v1.GetMeResponse\"\x12\x8a\xb5\x18\x00\x82\xd3\xe4\x93\x02\b\x12\x06/v1/me\x12h\n" +
+	"\n" +
+	"CreateUser\x12\x1b.oauth.v1.CreateUserRequest\x1a\x1c.oauth.v1.CreateUserResponse\"\x1f\x8a\xb5\x18\a\n" +
+	"\x05admin\x82\xd3\xe4\x93\x02\x0e:\x01*\"\t/v1/users\x12}\n" +
+	"\vDisableUser\x12\x1c.oauth.v1.DisableUserRequest\x1a\x1d.oauth.v1.DisableUserResponse\"1\x8a\xb5\x18\a\n" +
+	"\x05admin\x82\xd3\xe4\x93\x02 :\x01*\"\x1b/v1/users/{user_id}:disable\x12y\n" +
+	"\n" +
+	"EnableUser\x12\x1b.oauth.v1.EnableUserRequest\x1a\x1c.oauth.v1.EnableUserResponse\"0\x8a\xb5\x18\a\n" +
+	"\x05admin\x82\xd3\xe4\x93\x02\x1f:\x01*\"\x1a/v1/users/{user_id}:enable\x12o\n" +
+	"\n" +
+	"DeleteUser\x12\x1b.oauth.v1.DeleteUserRequest\x1a\x1c.oauth.v1.DeleteUserResponse\"&\x8a\xb5\x18\a\n" +
+	"\x05admin\x82\xd3\xe4\x93\x02\x15*\x13/v1/users/{user_id}\x12f\n" +
+	"\n" +
+	"ListGroups\x12\x1b.oauth.v1.ListGroupsRequest\x1a\x1c.oauth.v1.ListGroupsResponse\"\x1d\x8a\xb5\x18\a\n" +
+	"\x05admin\x82\xd3\xe4\x93\x02\f\x12\n" +
+	"/v1/groups\x12\x88\x01\n" +
+	"\x10ListGroupMembers\x12!.oauth.v1.ListGroupMembersRequest\x1a\".oauth.v1.ListGroupMembersResponse\"-\x8a\xb5\x18\a\n" +
+	"\x05admin\x82\xd3\xe4\x93\x02\x1c\x12\x1a/v1/groups/{group}/members\x12\x85\x01\n" +
+	"\x0eAddUserToGroup\x12\x1f.oauth.v1.AddUserToGroupRequest\x1a .oauth.v1.AddUserToGroupResponse\"0\x8a\xb5\x18\a\n" +
+	"\x05admin\x82\xd3\xe4\x93\x02\x1f:\x01*\"\x1a/v1/groups/{group}/members\x12\x9b\x01\n" +
+	"\x13RemoveUserFromGroup\x12$.oauth.v1.RemoveUserFromGroupRequest\x1a%.oauth.v1.RemoveUserFromGroupResponse\"7\x8a\xb5\x18\a\n" +
+	"\x05admin\x82\xd3\xe4\x93\x02&*$/v1/groups/{group}/members/{user_id}\x12}\n" +
+	"\vSetUserTier\x12\x1c.oauth.v1.SetUserTierRequest\x1a\x1d.oauth.v1.SetUserTierResponse\"1\x8a\xb5\x18\a\n" +
+	"\x05admin\x82\xd3\xe4\x93\x02 :\x01*\"\x1b/v1/users/{user_id}:setTier\x12\x99\x01\n" +
+	"\x13InvalidateUserCache\x12$.oauth.v1.InvalidateUserCacheRequest\x1a%.oauth.v1.InvalidateUserCacheResponse\"5\x8a\xb5\x18\a\n" +
+	"\x05admin\x82\xd3\xe4\x93\x02$:\x01*\"\x1f/v1/admin/user-cache:invalidate\x12p\n" +
+	"\x0eListMySessions\x12\x1f.oauth.v1.ListMySessionsRequest\x1a .oauth.v1.ListMySessionsResponse\"\x1b\x8a\xb5\x18\x00\x82\xd3\xe4\x93\x02\x11\x12\x0f/v1/me/sessions\x12\x80\x01\n" +
+	"\x0fRevokeMySession\x12 .oauth.v1.RevokeMySessionRequest\x1a!.oauth.v1.RevokeMySessionResponse\"(\x8a\xb5\x18\x00\x82\xd3\xe4\x93\x02\x1e*\x1c/v1/me/sessions/{session_id}\x12\x8a\x01\n" +
+	"\x10ListUserSessions\x12!.oauth.v1.ListUserSessionsRequest\x1a\".oauth.v1.ListUserSessionsResponse\"/\x8a\xb5\x18\a\n" +
+	"\x05admin\x82\xd3\xe4\x93\x02\x1e\x12\x1c/v1/users/{user_id}/sessions\x12\xa6\x01\n" +
+	"\x15RevokeAllUserSessions\x12&.oauth.v1.RevokeAllUserSessionsRequest\x1a'.oauth.v1.RevokeAllUserSessionsResponse\"<\x8a\xb5\x18\a\n" +
+	"\x05admin\x82\xd3\xe4\x93\x02+:\x01*\"&/v1/users/{user_id}/sessions:revokeAll\x12Y\n" +
+	"\x05Login\x12\x16.oauth.v1.LoginRequest\x1a\x17.oauth.v1.LoginResponse\"\x1f\x8a\xb5\x18\x02 \x01\x82\xd3\xe4\x93\x02\x13:\x01*\"\x0e/v1/auth:login\x12p\n" +
+	"\fRefreshToken\x12\x1d.oauth.v1.RefreshTokenRequest\x1a\x1e.oauth.v1.RefreshTokenResponse\"!\x8a\xb5\x18\x02 \x01\x82\xd3\xe4\x93\x02\x15:\x01*\"\x10/v1/auth:refresh\x12]\n" +
+	"\x06Logout\x12\x17.oauth.v1.LogoutRequest\x1a\x18.oauth.v1.LogoutResponse\" \x8a\xb5\x18\x02 \x01\x82\xd3\xe4\x93\x02\x14:\x01*\"\x0f/v1/auth:logoutB\xfc\x01\x92A\xc7\x01\x12\x9d\x01\n" +
 	"\bAuth API\x12=The Auth API handles authentication for the OmniAuth service.\"\v\n" +
 	"\tOmni Team*>\n" +
 	"\n" +
@@ -222,21 +3117,147 @@ func file_oauth_v1_auth_service_proto_rawDescGZIP() []byte {
 	return file_oauth_v1_auth_service_proto_rawDescData
 }
 
-var file_oauth_v1_auth_service_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_oauth_v1_auth_service_proto_msgTypes = make([]protoimpl.MessageInfo, 56)
 var file_oauth_v1_auth_service_proto_goTypes = []any{
-	(*PublicUser)(nil),      // 0: oauth.v1.PublicUser
-	(*GetUserRequest)(nil),  // 1: oauth.v1.GetUserRequest
-	(*GetUserResponse)(nil), // 2: oauth.v1.GetUserResponse
+	(*PublicUser)(nil),                    // 0: oauth.v1.PublicUser
+	(*GetUserRequest)(nil),                // 1: oauth.v1.GetUserRequest
+	(*GetUserResponse)(nil),               // 2: oauth.v1.GetUserResponse
+	(*LookupUserRequest)(nil),             // 3: oauth.v1.LookupUserRequest
+	(*LookupUserResponse)(nil),            // 4: oauth.v1.LookupUserResponse
+	(*ListUsersRequest)(nil),              // 5: oauth.v1.ListUsersRequest
+	(*ListUsersResponse)(nil),             // 6: oauth.v1.ListUsersResponse
+	(*BatchGetUsersRequest)(nil),          // 7: oauth.v1.BatchGetUsersRequest
+	(*BatchGetUsersResult)(nil),           // 8: oauth.v1.BatchGetUsersResult
+	(*BatchGetUsersResponse)(nil),         // 9: oauth.v1.BatchGetUsersResponse
+	(*GetMeRequest)(nil),                  // 10: oauth.v1.GetMeRequest
+	(*ClientRoles)(nil),                   // 11: oauth.v1.ClientRoles
+	(*Group)(nil),                         // 12: oauth.v1.Group
+	(*Me)(nil),                            // 13: oauth.v1.Me
+	(*GetMeResponse)(nil),                 // 14: oauth.v1.GetMeResponse
+	(*UpdateMeRequest)(nil),               // 15: oauth.v1.UpdateMeRequest
+	(*UpdateMeResponse)(nil),              // 16: oauth.v1.UpdateMeResponse
+	(*CreateUserRequest)(nil),             // 17: oauth.v1.CreateUserRequest
+	(*CreateUserResponse)(nil),            // 18: oauth.v1.CreateUserResponse
+	(*DisableUserRequest)(nil),            // 19: oauth.v1.DisableUserRequest
+	(*DisableUserResponse)(nil),           // 20: oauth.v1.DisableUserResponse
+	(*EnableUserRequest)(nil),             // 21: oauth.v1.EnableUserRequest
+	(*EnableUserResponse)(nil),            // 22: oauth.v1.EnableUserResponse
+	(*DeleteUserRequest)(nil),             // 23: oauth.v1.DeleteUserRequest
+	(*DeleteUserResponse)(nil),            // 24: oauth.v1.DeleteUserResponse
+	(*ListGroupsRequest)(nil),             // 25: oauth.v1.ListGroupsRequest
+	(*ListGroupsResponse)(nil),            // 26: oauth.v1.ListGroupsResponse
+	(*ListGroupMembersRequest)(nil),       // 27: oauth.v1.ListGroupMembersRequest
+	(*ListGroupMembersResponse)(nil),      // 28: oauth.v1.ListGroupMembersResponse
+	(*AddUserToGroupRequest)(nil),         // 29: oauth.v1.AddUserToGroupRequest
+	(*AddUserToGroupResponse)(nil),        // 30: oauth.v1.AddUserToGroupResponse
+	(*RemoveUserFromGroupRequest)(nil),    // 31: oauth.v1.RemoveUserFromGroupRequest
+	(*RemoveUserFromGroupResponse)(nil),   // 32: oauth.v1.RemoveUserFromGroupResponse
+	(*SetUserTierRequest)(nil),            // 33: oauth.v1.SetUserTierRequest
+	(*SetUserTierResponse)(nil),           // 34: oauth.v1.SetUserTierResponse
+	(*InvalidateUserCacheRequest)(nil),    // 35: oauth.v1.InvalidateUserCacheRequest
+	(*UserCacheStats)(nil),                // 36: oauth.v1.UserCacheStats
+	(*InvalidateUserCacheResponse)(nil),   // 37: oauth.v1.InvalidateUserCacheResponse
+	(*Token)(nil),                         // 38: oauth.v1.Token
+	(*LoginRequest)(nil),                  // 39: oauth.v1.LoginRequest
+	(*LoginResponse)(nil),                 // 40: oauth.v1.LoginResponse
+	(*RefreshTokenRequest)(nil),           // 41: oauth.v1.RefreshTokenRequest
+	(*RefreshTokenResponse)(nil),          // 42: oauth.v1.RefreshTokenResponse
+	(*LogoutRequest)(nil),                 // 43: oauth.v1.LogoutRequest
+	(*LogoutResponse)(nil),                // 44: oauth.v1.LogoutResponse
+	(*UserSession)(nil),                   // 45: oauth.v1.UserSession
+	(*ListMySessionsRequest)(nil),         // 46: oauth.v1.ListMySessionsRequest
+	(*ListMySessionsResponse)(nil),        // 47: oauth.v1.ListMySessionsResponse
+	(*RevokeMySessionRequest)(nil),        // 48: oauth.v1.RevokeMySessionRequest
+	(*RevokeMySessionResponse)(nil),       // 49: oauth.v1.RevokeMySessionResponse
+	(*ListUserSessionsRequest)(nil),       // 50: oauth.v1.ListUserSessionsRequest
+	(*ListUserSessionsResponse)(nil),      // 51: oauth.v1.ListUserSessionsResponse
+	(*RevokeAllUserSessionsRequest)(nil),  // 52: oauth.v1.RevokeAllUserSessionsRequest
+	(*RevokeAllUserSessionsResponse)(nil), // 53: oauth.v1.RevokeAllUserSessionsResponse
+	nil,                                   // 54: oauth.v1.Me.ClientRolesEntry
+	nil,                                   // 55: oauth.v1.SetUserTierResponse.ClientRolesEntry
+	(*timestamppb.Timestamp)(nil),         // 56: google.protobuf.Timestamp
+	(*fieldmaskpb.FieldMask)(nil),         // 57: google.protobuf.FieldMask
 }
 var file_oauth_v1_auth_service_proto_depIdxs = []int32{
-	0, // 0: oauth.v1.GetUserResponse.user:type_name -> oauth.v1.PublicUser
-	1, // 1: oauth.v1.AuthService.GetUser:input_type -> oauth.v1.GetUserRequest
-	2, // 2: oauth.v1.AuthService.GetUser:output_type -> oauth.v1.GetUserResponse
-	2, // [2:3] is the sub-list for method output_type
-	1, // [1:2] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	0,  // 0: oauth.v1.GetUserResponse.user:type_name -> oauth.v1.PublicUser
+	0,  // 1: oauth.v1.LookupUserResponse.user:type_name -> oauth.v1.PublicUser
+	0,  // 2: oauth.v1.ListUsersResponse.users:type_name -> oauth.v1.PublicUser
+	0,  // 3: oauth.v1.BatchGetUsersResult.user:type_name -> oauth.v1.PublicUser
+	8,  // 4: oauth.v1.BatchGetUsersResponse.results:type_name -> oauth.v1.BatchGetUsersResult
+	0,  // 5: oauth.v1.Me.user:type_name -> oauth.v1.PublicUser
+	54, // 6: oauth.v1.Me.client_roles:type_name -> oauth.v1.Me.ClientRolesEntry
+	12, // 7: oauth.v1.Me.groups:type_name -> oauth.v1.Group
+	56, // 8: oauth.v1.Me.created_at:type_name -> google.protobuf.Timestamp
+	13, // 9: oauth.v1.GetMeResponse.me:type_name -> oauth.v1.Me
+	0,  // 10: oauth.v1.UpdateMeRequest.user:type_name -> oauth.v1.PublicUser
+	57, // 11: oauth.v1.UpdateMeRequest.update_mask:type_name -> google.protobuf.FieldMask
+	0,  // 12: oauth.v1.UpdateMeResponse.user:type_name -> oauth.v1.PublicUser
+	0,  // 13: oauth.v1.CreateUserResponse.user:type_name -> oauth.v1.PublicUser
+	12, // 14: oauth.v1.ListGroupsResponse.groups:type_name -> oauth.v1.Group
+	0,  // 15: oauth.v1.ListGroupMembersResponse.users:type_name -> oauth.v1.PublicUser
+	56, // 16: oauth.v1.SetUserTierRequest.expires_at:type_name -> google.protobuf.Timestamp
+	56, // 17: oauth.v1.SetUserTierResponse.expires_at:type_name -> google.protobuf.Timestamp
+	55, // 18: oauth.v1.SetUserTierResponse.client_roles:type_name -> oauth.v1.SetUserTierResponse.ClientRolesEntry
+	36, // 19: oauth.v1.InvalidateUserCacheResponse.stats:type_name -> oauth.v1.UserCacheStats
+	38, // 20: oauth.v1.LoginResponse.token:type_name -> oauth.v1.Token
+	38, // 21: oauth.v1.RefreshTokenResponse.token:type_name -> oauth.v1.Token
+	56, // 22: oauth.v1.UserSession.start_time:type_name -> google.protobuf.Timestamp
+	56, // 23: oauth.v1.UserSession.last_access_time:type_name -> google.protobuf.Timestamp
+	45, // 24: oauth.v1.ListMySessionsResponse.sessions:type_name -> oauth.v1.UserSession
+	45, // 25: oauth.v1.ListUserSessionsResponse.sessions:type_name -> oauth.v1.UserSession
+	11, // 26: oauth.v1.Me.ClientRolesEntry.value:type_name -> oauth.v1.ClientRoles
+	11, // 27: oauth.v1.SetUserTierResponse.ClientRolesEntry.value:type_name -> oauth.v1.ClientRoles
+	1,  // 28: oauth.v1.AuthService.GetUser:input_type -> oauth.v1.GetUserRequest
+	3,  // 29: oauth.v1.AuthService.LookupUser:input_type -> oauth.v1.LookupUserRequest
+	5,  // 30: oauth.v1.AuthService.ListUsers:input_type -> oauth.v1.ListUsersRequest
+	15, // 31: oauth.v1.AuthService.UpdateMe:input_type -> oauth.v1.UpdateMeRequest
+	7,  // 32: oauth.v1.AuthService.BatchGetUsers:input_type -> oauth.v1.BatchGetUsersRequest
+	10, // 33: oauth.v1.AuthService.GetMe:input_type -> oauth.v1.GetMeRequest
+	17, // 34: oauth.v1.AuthService.CreateUser:input_type -> oauth.v1.CreateUserRequest
+	19, // 35: oauth.v1.AuthService.DisableUser:input_type -> oauth.v1.DisableUserRequest
+	21, // 36: oauth.v1.AuthService.EnableUser:input_type -> oauth.v1.EnableUserRequest
+	23, // 37: oauth.v1.AuthService.DeleteUser:input_type -> oauth.v1.DeleteUserRequest
+	25, // 38: oauth.v1.AuthService.ListGroups:input_type -> oauth.v1.ListGroupsRequest
+	27, // 39: oauth.v1.AuthService.ListGroupMembers:input_type -> oauth.v1.ListGroupMembersRequest
+	29, // 40: oauth.v1.AuthService.AddUserToGroup:input_type -> oauth.v1.AddUserToGroupRequest
+	31, // 41: oauth.v1.AuthService.RemoveUserFromGroup:input_type -> oauth.v1.RemoveUserFromGroupRequest
+	33, // 42: oauth.v1.AuthService.SetUserTier:input_type -> oauth.v1.SetUserTierRequest
+	35, // 43: oauth.v1.AuthService.InvalidateUserCache:input_type -> oauth.v1.InvalidateUserCacheRequest
+	46, // 44: oauth.v1.AuthService.ListMySessions:input_type -> oauth.v1.ListMySessionsRequest
+	48, // 45: oauth.v1.AuthService.RevokeMySession:input_type -> oauth.v1.RevokeMySessionRequest
+	50, // 46: oauth.v1.AuthService.ListUserSessions:input_type -> oauth.v1.ListUserSessionsRequest
+	52, // 47: oauth.v1.AuthService.RevokeAllUserSessions:input_type -> oauth.v1.RevokeAllUserSessionsRequest
+	39, // 48: oauth.v1.AuthService.Login:input_type -> oauth.v1.LoginRequest
+	41, // 49: oauth.v1.AuthService.RefreshToken:input_type -> oauth.v1.RefreshTokenRequest
+	43, // 50: oauth.v1.AuthService.Logout:input_type -> oauth.v1.LogoutRequest
+	2,  // 51: oauth.v1.AuthService.GetUser:output_type -> oauth.v1.GetUserResponse
+	4,  // 52: oauth.v1.AuthService.LookupUser:output_type -> oauth.v1.LookupUserResponse
+	6,  // 53: oauth.v1.AuthService.ListUsers:output_type -> oauth.v1.ListUsersResponse
+	16, // 54: oauth.v1.AuthService.UpdateMe:output_type -> oauth.v1.UpdateMeResponse
+	9,  // 55: oauth.v1.AuthService.BatchGetUsers:output_type -> oauth.v1.BatchGetUsersResponse
+	14, // 56: oauth.v1.AuthService.GetMe:output_type -> oauth.v1.GetMeResponse
+	18, // 57: oauth.v1.AuthService.CreateUser:output_type -> oauth.v1.CreateUserResponse
+	20, // 58: oauth.v1.AuthService.DisableUser:output_type -> oauth.v1.DisableUserResponse
+	22, // 59: oauth.v1.AuthService.EnableUser:output_type -> oauth.v1.EnableUserResponse
+	24, // 60: oauth.v1.AuthService.DeleteUser:output_type -> oauth.v1.DeleteUserResponse
+	26, // 61: oauth.v1.AuthService.ListGroups:output_type -> oauth.v1.ListGroupsResponse
+	28, // 62: oauth.v1.AuthService.ListGroupMembers:output_type -> oauth.v1.ListGroupMembersResponse
+	30, // 63: oauth.v1.AuthService.AddUserToGroup:output_type -> oauth.v1.AddUserToGroupResponse
+	32, // 64: oauth.v1.AuthService.RemoveUserFromGroup:output_type -> oauth.v1.RemoveUserFromGroupResponse
+	34, // 65: oauth.v1.AuthService.SetUserTier:output_type -> oauth.v1.SetUserTierResponse
+	37, // 66: oauth.v1.AuthService.InvalidateUserCache:output_type -> oauth.v1.InvalidateUserCacheResponse
+	47, // 67: oauth.v1.AuthService.ListMySessions:output_type -> oauth.v1.ListMySessionsResponse
+	49, // 68: oauth.v1.AuthService.RevokeMySession:output_type -> oauth.v1.RevokeMySessionResponse
+	51, // 69: oauth.v1.AuthService.ListUserSessions:output_type -> oauth.v1.ListUserSessionsResponse
+	53, // 70: oauth.v1.AuthService.RevokeAllUserSessions:output_type -> oauth.v1.RevokeAllUserSessionsResponse
+	40, // 71: oauth.v1.AuthService.Login:output_type -> oauth.v1.LoginResponse
+	42, // 72: oauth.v1.AuthService.RefreshToken:output_type -> oauth.v1.RefreshTokenResponse
+	44, // 73: oauth.v1.AuthService.Logout:output_type -> oauth.v1.LogoutResponse
+	51, // [51:74] is the sub-list for method output_type
+	28, // [28:51] is the sub-list for method input_type
+	28, // [28:28] is the sub-list for extension type_name
+	28, // [28:28] is the sub-list for extension extendee
+	0,  // [0:28] is the sub-list for field type_name
 }
 
 func init() { file_oauth_v1_auth_service_proto_init() }
@@ -244,13 +3265,16 @@ func file_oauth_v1_auth_service_proto_init() {
 	if File_oauth_v1_auth_service_proto != nil {
 		return
 	}
+	file_oauth_v1_options_proto_init()
+	file_oauth_v1_auth_service_proto_msgTypes[5].OneofWrappers = []any{}
+	file_oauth_v1_auth_service_proto_msgTypes[6].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_oauth_v1_auth_service_proto_rawDesc), len(file_oauth_v1_auth_service_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   3,
+			NumMessages:   56,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
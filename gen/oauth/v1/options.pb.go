@@ -0,0 +1,180 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: oauth/v1/options.proto
+
+package oauth
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	descriptorpb "google.golang.org/protobuf/types/descriptorpb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// AuthPolicy declares who may call an RPC. Every method served by omniauth must carry one,
+// methods without a policy are denied and the server refuses to start.
+type AuthPolicy struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Roles of the omniauth client the caller needs, any one of them grants access.
+	Roles []string `protobuf:"bytes,1,rep,name=roles,proto3" json:"roles,omitempty"`
+	// Token scopes the caller needs, all of them are required.
+	Scopes []string `protobuf:"bytes,2,rep,name=scopes,proto3" json:"scopes,omitempty"`
+	// Public methods can be called without a token.
+	Public bool `protobuf:"varint,3,opt,name=public,proto3" json:"public,omitempty"`
+	// Anonymous methods ignore the bearer token and never have a caller, e.g. the token endpoints,
+	// which clients call with an expired access token. Implies public.
+	Anonymous     bool `protobuf:"varint,4,opt,name=anonymous,proto3" json:"anonymous,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AuthPolicy) Reset() {
+	*x = AuthPolicy{}
+	mi := &file_oauth_v1_options_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AuthPolicy) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuthPolicy) ProtoMessage() {}
+
+func (x *AuthPolicy) ProtoReflect() protoreflect.Message {
+	mi := &file_oauth_v1_options_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AuthPolicy.ProtoReflect.Descriptor instead.
+func (*AuthPolicy) Descriptor() ([]byte, []int) {
+	return file_oauth_v1_options_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *AuthPolicy) GetRoles() []string {
+	if x != nil {
+		return x.Roles
+	}
+	return nil
+}
+
+func (x *AuthPolicy) GetScopes() []string {
+	if x != nil {
+		return x.Scopes
+	}
+	return nil
+}
+
+func (x *AuthPolicy) GetPublic() bool {
+	if x != nil {
+		return x.Public
+	}
+	return false
+}
+
+func (x *AuthPolicy) GetAnonymous() bool {
+	if x != nil {
+		return x.Anonymous
+	}
+	return false
+}
+
+var file_oauth_v1_options_proto_extTypes = []protoimpl.ExtensionInfo{
+	{
+		ExtendedType:  (*descriptorpb.MethodOptions)(nil),
+		ExtensionType: (*AuthPolicy)(nil),
+		Field:         50001,
+		Name:          "oauth.v1.auth",
+		Tag:           "bytes,50001,opt,name=auth",
+		Filename:      "oauth/v1/options.proto",
+	},
+}
+
+// Extension fields to descriptorpb.MethodOptions.
+var (
+	// optional oauth.v1.AuthPolicy auth = 50001;
+	E_Auth = &file_oauth_v1_options_proto_extTypes[0]
+)
+
+var File_oauth_v1_options_proto protoreflect.FileDescriptor
+
+const file_oauth_v1_options_proto_rawDesc = "" +
+	"\n" +
+	"\x16oauth/v1/options.proto\x12\boauth.v1\x1a google/protobuf/descriptor.proto\"p\n" +
+	"\n" +
+	"AuthPolicy\x12\x14\n" +
+	"\x05roles\x18\x01 \x03(\tR\x05roles\x12\x16\n" +
+	"\x06scopes\x18\x02 \x03(\tR\x06scopes\x12\x16\n" +
+	"\x06public\x18\x03 \x01(\bR\x06public\x12\x1c\n" +
+	"\tanonymous\x18\x04 \x01(\bR\tanonymous:J\n" +
+	"\x04auth\x12\x1e.google.protobuf.MethodOptions\x18ц\x03 \x01(\v2\x14.oauth.v1.AuthPolicyR\x04authB1Z/github.com/omnsight/omniauth/gen/oauth/v1;oauthb\x06proto3"
+
+var (
+	file_oauth_v1_options_proto_rawDescOnce sync.Once
+	file_oauth_v1_options_proto_rawDescData []byte
+)
+
+func file_oauth_v1_options_proto_rawDescGZIP() []byte {
+	file_oauth_v1_options_proto_rawDescOnce.Do(func() {
+		file_oauth_v1_options_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_oauth_v1_options_proto_rawDesc), len(file_oauth_v1_options_proto_rawDesc)))
+	})
+	return file_oauth_v1_options_proto_rawDescData
+}
+
+var file_oauth_v1_options_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_oauth_v1_options_proto_goTypes = []any{
+	(*AuthPolicy)(nil),                 // 0: oauth.v1.AuthPolicy
+	(*descriptorpb.MethodOptions)(nil), // 1: google.protobuf.MethodOptions
+}
+var file_oauth_v1_options_proto_depIdxs = []int32{
+	1, // 0: oauth.v1.auth:extendee -> google.protobuf.MethodOptions
+	0, // 1: oauth.v1.auth:type_name -> oauth.v1.AuthPolicy
+	2, // [2:2] is the sub-list for method output_type
+	2, // [2:2] is the sub-list for method input_type
+	1, // [1:2] is the sub-list for extension type_name
+	0, // [0:1] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_oauth_v1_options_proto_init() }
+func file_oauth_v1_options_proto_init() {
+	if File_oauth_v1_options_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_oauth_v1_options_proto_rawDesc), len(file_oauth_v1_options_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   1,
+			NumExtensions: 1,
+			NumServices:   0,
+		},
+		GoTypes:           file_oauth_v1_options_proto_goTypes,
+		DependencyIndexes: file_oauth_v1_options_proto_depIdxs,
+		MessageInfos:      file_oauth_v1_options_proto_msgTypes,
+		ExtensionInfos:    file_oauth_v1_options_proto_extTypes,
+	}.Build()
+	File_oauth_v1_options_proto = out.File
+	file_oauth_v1_options_proto_goTypes = nil
+	file_oauth_v1_options_proto_depIdxs = nil
+}
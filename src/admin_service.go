@@ -0,0 +1,230 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/Nerzal/gocloak/v13"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/omnsight/omnauth/gen/oauth/v1"
+	"github.com/omnsight/omnauth/src/utils"
+)
+
+// User attributes written by DisableUser
+const (
+	disabledReasonAttribute = "disabledReason"
+	disabledAtAttribute     = "disabledAt"
+	disabledByAttribute     = "disabledBy"
+)
+
+// createdByAttribute holds the admin who created a user with CreateUser, the unverified user cleanup keeps those users
+const createdByAttribute = "createdBy"
+
+// maxDisableReasonLength bounds the reason stored as a user attribute
+const maxDisableReasonLength = 1000
+
+func (s *AuthService) CreateUser(ctx context.Context, req *oauth.CreateUserRequest) (resp *oauth.CreateUserResponse, err error) {
+	var userId string
+	defer func() {
+		utils.Audit(ctx, s.audit, utils.AuditCreateUser, userId, map[string]string{"username": req.GetUsername()}, err)
+	}()
+
+	identity, err := utils.GetIdentity(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateNewUser(req); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	// Resolve groups first so a typo doesn't leave a half set up user behind
+	groupIds := make([]string, 0, len(req.GetGroups()))
+	for _, path := range req.GetGroups() {
+		group, err := s.cloakHelper.GetGroupByPath(ctx, path)
+		if utils.IsNotFound(err) {
+			return nil, status.Errorf(codes.InvalidArgument, "group %s does not exist", path)
+		}
+		if err != nil {
+			return nil, utils.KeycloakError(ctx, err)
+		}
+		groupIds = append(groupIds, safeStr(group.ID))
+	}
+
+	user := gocloak.User{
+		Username:      gocloak.StringP(req.GetUsername()),
+		FirstName:     gocloak.StringP(req.GetFirstname()),
+		LastName:      gocloak.StringP(req.GetLastname()),
+		Enabled:       gocloak.BoolP(true),
+		EmailVerified: gocloak.BoolP(req.GetEmailVerified()),
+	}
+	if req.GetEmail() != "" {
+		user.Email = gocloak.StringP(req.GetEmail())
+	}
+	setAttribute(&user, createdByAttribute, identity.UserID)
+	if userId, err = s.cloakHelper.CreateUser(ctx, user); err != nil {
+		return nil, utils.KeycloakError(ctx, err)
+	}
+	user.ID = gocloak.StringP(userId)
+
+	if err := s.setUpUser(ctx, userId, req.GetTemporaryPassword(), groupIds); err != nil {
+		// Roll back so the request can be retried as is
+		if deleteErr := s.cloakHelper.DeleteUser(context.WithoutCancel(ctx), userId); deleteErr != nil {
+			utils.GetLogger(ctx).WithError(deleteErr).Errorf("failed to roll back creation of user %s", userId)
+		}
+		return nil, utils.KeycloakError(ctx, err)
+	}
+
+	return &oauth.CreateUserResponse{User: s.profiles.PublicUser(identity, &user)}, nil
+}
+
+// setUpUser sets the password and groups of a new user
+func (s *AuthService) setUpUser(ctx context.Context, userId, temporaryPassword string, groupIds []string) error {
+	if temporaryPassword != "" {
+		if err := s.cloakHelper.SetPassword(ctx, userId, temporaryPassword, true); err != nil {
+			return err
+		}
+	}
+	for _, groupId := range groupIds {
+		if err := s.cloakHelper.AddUserToGroup(ctx, userId, groupId); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateNewUser(req *oauth.CreateUserRequest) error {
+	if err := utils.ValidateUsername("username", req.GetUsername()); err != nil {
+		return err
+	}
+	if req.GetEmail() != "" {
+		if err := utils.ValidateEmail("email", req.GetEmail()); err != nil {
+			return err
+		}
+	}
+	if err := utils.ValidateName("firstname", req.GetFirstname()); err != nil {
+		return err
+	}
+	return utils.ValidateName("lastname", req.GetLastname())
+}
+
+func (s *AuthService) DisableUser(ctx context.Context, req *oauth.DisableUserRequest) (resp *oauth.DisableUserResponse, err error) {
+	defer func() {
+		utils.Audit(ctx, s.audit, utils.AuditDisableUser, req.GetUserId(), map[string]string{"reason": req.GetReason()}, err)
+	}()
+
+	switch {
+	case req.GetReason() == "":
+		return nil, status.Error(codes.InvalidArgument, "reason is required")
+	case len(req.GetReason()) > maxDisableReasonLength:
+		return nil, status.Errorf(codes.InvalidArgument, "reason must be at most %d bytes", maxDisableReasonLength)
+	}
+	identity, err := s.otherUser(ctx, req.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.updateUser(ctx, req.GetUserId(), func(user *gocloak.User) {
+		user.Enabled = gocloak.BoolP(false)
+		setAttribute(user, disabledReasonAttribute, req.GetReason())
+		setAttribute(user, disabledAtAttribute, time.Now().UTC().Format(time.RFC3339))
+		setAttribute(user, disabledByAttribute, identity.UserID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Disabling doesn't end existing sessions, their tokens stay valid until they expire
+	if err := s.cloakHelper.LogoutUser(ctx, req.GetUserId()); err != nil {
+		utils.GetLogger(ctx).WithError(err).Warnf("failed to logout disabled user %s", req.GetUserId())
+	}
+	// Verified tokens aren't checked against Keycloak, deny them here too
+	s.denylist.RevokeSubject(req.GetUserId(), time.Now())
+	return &oauth.DisableUserResponse{}, nil
+}
+
+func (s *AuthService) EnableUser(ctx context.Context, req *oauth.EnableUserRequest) (resp *oauth.EnableUserResponse, err error) {
+	defer func() {
+		utils.Audit(ctx, s.audit, utils.AuditEnableUser, req.GetUserId(), nil, err)
+	}()
+
+	if _, err := s.otherUser(ctx, req.GetUserId()); err != nil {
+		return nil, err
+	}
+
+	err = s.updateUser(ctx, req.GetUserId(), func(user *gocloak.User) {
+		user.Enabled = gocloak.BoolP(true)
+		setAttribute(user, disabledReasonAttribute, "")
+		setAttribute(user, disabledAtAttribute, "")
+		setAttribute(user, disabledByAttribute, "")
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &oauth.EnableUserResponse{}, nil
+}
+
+func (s *AuthService) DeleteUser(ctx context.Context, req *oauth.DeleteUserRequest) (resp *oauth.DeleteUserResponse, err error) {
+	defer func() {
+		utils.Audit(ctx, s.audit, utils.AuditDeleteUser, req.GetUserId(), nil, err)
+	}()
+
+	if _, err := s.otherUser(ctx, req.GetUserId()); err != nil {
+		return nil, err
+	}
+
+	// End the sessions first, Keycloak can't log out a deleted user
+	if err := s.cloakHelper.LogoutUser(ctx, req.GetUserId()); err != nil {
+		utils.GetLogger(ctx).WithError(err).Warnf("failed to logout deleted user %s", req.GetUserId())
+	}
+	if err := s.cloakHelper.DeleteUser(ctx, req.GetUserId()); err != nil {
+		return nil, utils.KeycloakError(ctx, err)
+	}
+	s.denylist.RevokeSubject(req.GetUserId(), time.Now())
+	s.userCache.Invalidate(req.GetUserId())
+	return &oauth.DeleteUserResponse{}, nil
+}
+
+// otherUser returns the caller after checking that userId is set and isn't the caller,
+// so admins can't lock themselves out
+func (s *AuthService) otherUser(ctx context.Context, userId string) (*utils.Identity, error) {
+	identity, err := utils.GetIdentity(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if userId == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+	if userId == identity.UserID {
+		return nil, status.Error(codes.FailedPrecondition, "admins can't change their own account")
+	}
+	return identity, nil
+}
+
+// updateUser applies change to the current representation of a user and saves it
+func (s *AuthService) updateUser(ctx context.Context, userId string, change func(user *gocloak.User)) error {
+	// Don't go through the cache, cached users are shared
+	user, err := s.cloakHelper.GetUserProfile(ctx, userId)
+	if err != nil {
+		return utils.KeycloakError(ctx, err)
+	}
+	change(user)
+	if err := s.cloakHelper.UpdateUser(ctx, *user); err != nil {
+		return utils.KeycloakError(ctx, err)
+	}
+	s.userCache.Invalidate(userId)
+	return nil
+}
+
+// setAttribute sets a single valued user attribute, an empty value removes it
+func setAttribute(user *gocloak.User, name, value string) {
+	if user.Attributes == nil {
+		user.Attributes = &map[string][]string{}
+	}
+	if value == "" {
+		delete(*user.Attributes, name)
+		return
+	}
+	(*user.Attributes)[name] = []string{value}
+}
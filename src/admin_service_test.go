@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/omnsight/omnauth/gen/oauth/v1"
+	"github.com/omnsight/omnauth/src/utils"
+)
+
+func TestDisableAndDeleteUserRevokeTokens(t *testing.T) {
+	kc := newFakeUserAdmin(t)
+	kc.addUser("disabled", "disabled@example.com", true, time.Hour)
+	kc.addUser("deleted", "deleted@example.com", true, time.Hour)
+	s := kc.newService(t)
+	admin := utils.WithIdentity(context.Background(), utils.NewIdentity(jwt.MapClaims{"sub": "admin-1"}, "omniauth"))
+	issuedAt := time.Now().Add(-time.Minute)
+
+	if _, err := s.DisableUser(admin, &oauth.DisableUserRequest{UserId: "disabled", Reason: "abuse"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := s.DeleteUser(admin, &oauth.DeleteUserRequest{UserId: "deleted"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, userID := range []string{"disabled", "deleted"} {
+		if !s.denylist.IsRevoked("", userID, issuedAt) {
+			t.Errorf("expected the tokens of %s to be revoked", userID)
+		}
+	}
+	if len(kc.loggedOut) != 2 {
+		t.Errorf("expected both users to be logged out, got %v", kc.loggedOut)
+	}
+	if s.denylist.IsRevoked("", "admin-1", issuedAt) {
+		t.Error("expected the tokens of other users to stay valid")
+	}
+}
@@ -2,14 +2,19 @@ package main
 
 import (
 	"context"
+	"slices"
 	"testing"
 	"time"
 
 	"github.com/Nerzal/gocloak/v13"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/omnsight/omnauth/gen/oauth/v1"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 )
 
 const (
@@ -109,6 +114,132 @@ func TestIntegration(t *testing.T) {
 		t.Errorf("Mismatch in Username: expected %s, got %s", testUser, resp.User.Username)
 	}
 
+	// --- 2. get me ---
+	meResp, err := authClient.GetMe(authCtx, &oauth.GetMeRequest{})
+	if err != nil {
+		t.Fatalf("Failed to get me: %v", err)
+	}
+	if meResp.Me.User.Id != adminUserID {
+		t.Errorf("Mismatch in User ID: expected %s, got %s", adminUserID, meResp.Me.User.Id)
+	}
+	if !slices.Contains(meResp.Me.ClientRoles[clientID].GetRoles(), "admin") {
+		t.Errorf("Expected admin role for %s, got %v", clientID, meResp.Me.ClientRoles)
+	}
+
+	// --- 3. lookup user ---
+	lookupResp, err := authClient.LookupUser(authCtx, &oauth.LookupUserRequest{Username: testUser})
+	if err != nil {
+		t.Fatalf("Failed to lookup user: %v", err)
+	}
+	if lookupResp.User.Id != testUserID {
+		t.Errorf("Mismatch in User ID: expected %s, got %s", testUserID, lookupResp.User.Id)
+	}
+	if _, err := authClient.LookupUser(authCtx, &oauth.LookupUserRequest{Username: testUser[:2]}); status.Code(err) != codes.NotFound {
+		t.Errorf("Expected prefix of a username not to match, got %v", err)
+	}
+
+	// --- 4. update me ---
+	updateResp, err := authClient.UpdateMe(authCtx, &oauth.UpdateMeRequest{
+		User:       &oauth.PublicUser{Lastname: "User"},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"lastname"}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to update me: %v", err)
+	}
+	if updateResp.User.Lastname != "User" || updateResp.User.Firstname != adminUser {
+		t.Errorf("Unexpected updated user: %v", updateResp.User)
+	}
+	if _, err := authClient.UpdateMe(authCtx, &oauth.UpdateMeRequest{
+		User:       &oauth.PublicUser{Username: "root"},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"username"}},
+	}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected username update to be rejected, got %v", err)
+	}
+
+	// --- 5. group members ---
+	membersResp, err := authClient.ListGroupMembers(authCtx, &oauth.ListGroupMembersRequest{Group: groupName})
+	if err != nil {
+		t.Fatalf("Failed to list group members: %v", err)
+	}
+	if !slices.ContainsFunc(membersResp.Users, func(u *oauth.PublicUser) bool { return u.Id == adminUserID }) {
+		t.Errorf("Expected %s to be a member of %s, got %v", adminUser, groupName, membersResp.Users)
+	}
+
+	// --- 6. set user tier ---
+	tierResp, err := authClient.SetUserTier(authCtx, &oauth.SetUserTierRequest{UserId: testUserID, Tier: "pro"})
+	if err != nil {
+		t.Fatalf("Failed to set user tier: %v", err)
+	}
+	if !slices.Contains(tierResp.ClientRoles[clientID].GetRoles(), "pro") {
+		t.Errorf("Expected pro role after upgrade, got %v", tierResp.ClientRoles)
+	}
+	tierResp, err = authClient.SetUserTier(authCtx, &oauth.SetUserTierRequest{UserId: testUserID, Tier: "default"})
+	if err != nil {
+		t.Fatalf("Failed to set user tier: %v", err)
+	}
+	if slices.Contains(tierResp.ClientRoles[clientID].GetRoles(), "pro") {
+		t.Errorf("Expected no pro role after downgrade, got %v", tierResp.ClientRoles)
+	}
+
+	// --- 7. login, refresh and logout ---
+	loginResp, err := authClient.Login(ctx, &oauth.LoginRequest{Username: testUser, Password: userPass})
+	if err != nil {
+		t.Fatalf("Failed to login: %v", err)
+	}
+	if loginResp.Token.GetAccessToken() == "" || loginResp.Token.GetRefreshToken() == "" {
+		t.Errorf("Expected access and refresh tokens, got %v", loginResp.Token)
+	}
+	if _, err := authClient.Login(ctx, &oauth.LoginRequest{Username: testUser, Password: "wrong"}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Expected Unauthenticated for a wrong password, got %v", err)
+	}
+	// Clients refresh once their access token expired, they may still send it along
+	expired, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub": testUserID,
+		"exp": time.Now().Add(-time.Minute).Unix(),
+	}).SignedString([]byte("expired"))
+	expiredCtx := metadata.NewOutgoingContext(ctx, metadata.Pairs("authorization", "Bearer "+expired))
+	refreshResp, err := authClient.RefreshToken(expiredCtx, &oauth.RefreshTokenRequest{RefreshToken: loginResp.Token.GetRefreshToken()})
+	if err != nil {
+		t.Fatalf("Failed to refresh token with an expired bearer: %v", err)
+	}
+	if _, err := authClient.Logout(ctx, &oauth.LogoutRequest{RefreshToken: refreshResp.Token.GetRefreshToken()}); err != nil {
+		t.Fatalf("Failed to logout: %v", err)
+	}
+	if _, err := authClient.RefreshToken(ctx, &oauth.RefreshTokenRequest{RefreshToken: refreshResp.Token.GetRefreshToken()}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Expected Unauthenticated for a revoked refresh token, got %v", err)
+	}
+
+	// --- 8. sessions ---
+	sessionToken, err := client.Login(ctx, clientID, clientSecret, realmName, testUser, userPass)
+	if err != nil {
+		t.Fatalf("Failed to login as test user: %v", err)
+	}
+	sessionCtx := metadata.NewOutgoingContext(ctx, metadata.Pairs("authorization", "Bearer "+sessionToken.AccessToken))
+	sessionsResp, err := authClient.ListMySessions(sessionCtx, &oauth.ListMySessionsRequest{})
+	if err != nil {
+		t.Fatalf("Failed to list my sessions: %v", err)
+	}
+	current := slices.IndexFunc(sessionsResp.Sessions, func(s *oauth.UserSession) bool { return s.Current })
+	if current < 0 || !slices.Contains(sessionsResp.Sessions[current].Clients, clientID) {
+		t.Fatalf("Expected the current session among %v", sessionsResp.Sessions)
+	}
+	if _, err := authClient.RevokeMySession(sessionCtx, &oauth.RevokeMySessionRequest{SessionId: sessionsResp.Sessions[current].Id}); err != nil {
+		t.Fatalf("Failed to revoke my session: %v", err)
+	}
+	if _, err := authClient.GetMe(sessionCtx, &oauth.GetMeRequest{}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Expected the revoked session's token to be rejected, got %v", err)
+	}
+	if _, err := authClient.RevokeAllUserSessions(authCtx, &oauth.RevokeAllUserSessionsRequest{UserId: testUserID}); err != nil {
+		t.Fatalf("Failed to revoke all user sessions: %v", err)
+	}
+	userSessionsResp, err := authClient.ListUserSessions(authCtx, &oauth.ListUserSessionsRequest{UserId: testUserID})
+	if err != nil {
+		t.Fatalf("Failed to list user sessions: %v", err)
+	}
+	if len(userSessionsResp.Sessions) != 0 {
+		t.Errorf("Expected no sessions after revoking all, got %v", userSessionsResp.Sessions)
+	}
+
 	t.Log("Integration test completed successfully.")
 }
 
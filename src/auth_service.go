@@ -2,48 +2,451 @@ package main
 
 import (
 	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Nerzal/gocloak/v13"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/omnsight/omnauth/gen/oauth/v1"
 	"github.com/omnsight/omnauth/src/utils"
 )
 
+const (
+	// maxBatchGetUsers is the largest batch BatchGetUsers accepts
+	maxBatchGetUsers = 500
+	// requiredActionVerifyEmail makes Keycloak ask for email verification on next login
+	requiredActionVerifyEmail = "VERIFY_EMAIL"
+	// Page sizes of list RPCs
+	defaultPageSize = 50
+	maxPageSize     = 200
+)
+
 type AuthService struct {
 	oauth.UnimplementedAuthServiceServer
 	cloakHelper *utils.CloakHelper
+	userCache   *utils.UserCache
+	profiles    *utils.ProfilePolicy
+	audit       utils.AuditSink
+	tiers       *utils.TierConfig
+	denylist    *utils.TokenDenylist
 }
 
-func NewAuthService(client *utils.CloakHelper) (*AuthService, error) {
+func NewAuthService(client *utils.CloakHelper, denylist *utils.TokenDenylist) (*AuthService, error) {
+	userCache, err := utils.NewUserCache(client)
+	if err != nil {
+		return nil, err
+	}
+	tiers, err := utils.NewTierConfig()
+	if err != nil {
+		return nil, err
+	}
+
 	service := &AuthService{
 		cloakHelper: client,
+		userCache:   userCache,
+		profiles:    utils.NewProfilePolicy(),
+		audit:       utils.LogAuditSink{},
+		tiers:       tiers,
+		denylist:    denylist,
 	}
 	return service, nil
 }
 
 func (s *AuthService) GetUser(ctx context.Context, req *oauth.GetUserRequest) (*oauth.GetUserResponse, error) {
-	userId, userRoles, err := utils.GetUser(ctx)
+	identity, err := utils.GetIdentity(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	logger := utils.GetLogger(ctx)
-	logger.Infof("[%s, %v] requests to view user profile %s", userId, userRoles, req.GetUserId())
+	logger.Infof("[%s, %v] requests to view user profile %s", identity.UserID, identity.Roles(), req.GetUserId())
 
-	user, err := s.cloakHelper.GetUserProfile(ctx, req.GetUserId())
+	user, err := s.userCache.GetUserProfile(ctx, req.GetUserId())
 	if err != nil {
-		return nil, err
+		return nil, utils.KeycloakError(ctx, err)
 	}
 
 	return &oauth.GetUserResponse{
-		User: &oauth.PublicUser{
-			Id:        safeStr(user.ID),
-			Username:  safeStr(user.Username),
-			Firstname: safeStr(user.FirstName),
-			Lastname:  safeStr(user.LastName),
-			Email:     safeStr(user.Email),
+		User: s.profiles.PublicUser(identity, user),
+	}, nil
+}
+
+func (s *AuthService) LookupUser(ctx context.Context, req *oauth.LookupUserRequest) (*oauth.LookupUserResponse, error) {
+	identity, err := utils.GetIdentity(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	params := gocloak.GetUsersParams{
+		Exact: gocloak.BoolP(true),
+		// Two are enough to tell a single match from an ambiguous one
+		Max: gocloak.IntP(2),
+	}
+	var value string
+	switch {
+	case (req.GetUsername() == "") == (req.GetEmail() == ""):
+		return nil, status.Error(codes.InvalidArgument, "exactly one of username and email is required")
+	case req.GetUsername() != "":
+		value = req.GetUsername()
+		params.Username = gocloak.StringP(value)
+	default:
+		value = req.GetEmail()
+		if !identity.HasClientRole(utils.AdminRole) && !strings.EqualFold(value, identity.Email) {
+			return nil, utils.StatusWithReason(codes.PermissionDenied, utils.ReasonMissingRole, "only admins may look up other users by email")
+		}
+		params.Email = gocloak.StringP(value)
+	}
+
+	logger := utils.GetLogger(ctx)
+	logger.Infof("[%s, %v] looks up user %s", identity.UserID, identity.Roles(), value)
+
+	users, _, err := s.cloakHelper.ListUsers(ctx, params)
+	if err != nil {
+		return nil, utils.KeycloakError(ctx, err)
+	}
+
+	var matches []*gocloak.User
+	for _, user := range users {
+		// Keycloak compares case-insensitively, so does the double check
+		field := user.Username
+		if req.GetEmail() != "" {
+			field = user.Email
+		}
+		if strings.EqualFold(safeStr(field), value) {
+			matches = append(matches, user)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, utils.StatusWithReason(codes.NotFound, utils.ReasonNotFound, "user not found")
+	case 1:
+		return &oauth.LookupUserResponse{User: s.profiles.PublicUser(identity, matches[0])}, nil
+	default:
+		return nil, status.Error(codes.FailedPrecondition, "more than one user matches")
+	}
+}
+
+func (s *AuthService) ListUsers(ctx context.Context, req *oauth.ListUsersRequest) (*oauth.ListUsersResponse, error) {
+	identity, err := utils.GetIdentity(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	exact := req.GetUsername() != "" || req.GetEmail() != ""
+	switch {
+	case req.GetQuery() != "" && (exact || req.GetEmailDomain() != ""):
+		return nil, status.Error(codes.InvalidArgument, "query can't be combined with username, email or email_domain")
+	case req.GetEmailDomain() != "" && exact:
+		return nil, status.Error(codes.InvalidArgument, "email_domain can't be combined with username or email")
+	case req.GetGroup() != "" && (req.GetQuery() != "" || exact || req.GetEmailDomain() != "" || req.Enabled != nil):
+		return nil, status.Error(codes.InvalidArgument, "group can't be combined with other filters")
+	}
+
+	enabled := ""
+	if req.Enabled != nil {
+		enabled = strconv.FormatBool(req.GetEnabled())
+	}
+	filters := []string{req.GetQuery(), req.GetUsername(), req.GetEmail(), req.GetEmailDomain(), enabled, req.GetGroup()}
+	first, max, err := pagination(req.GetPageSize(), req.GetPageToken(), filters...)
+	if err != nil {
+		return nil, err
+	}
+
+	logger := utils.GetLogger(ctx)
+	logger.Infof("[%s, %v] lists users (first %d, max %d)", identity.UserID, identity.Roles(), first, max)
+
+	var users []*gocloak.User
+	total := -1
+	if req.GetGroup() != "" {
+		group, err := s.resolveGroup(ctx, req.GetGroup())
+		if err != nil {
+			return nil, err
+		}
+		if users, err = s.cloakHelper.GetGroupMembers(ctx, safeStr(group.ID), first, max); err != nil {
+			return nil, utils.KeycloakError(ctx, err)
+		}
+		if len(users) < max {
+			total = first + len(users)
+		}
+	} else {
+		params := gocloak.GetUsersParams{
+			First:   gocloak.IntP(first),
+			Max:     gocloak.IntP(max),
+			Enabled: req.Enabled,
+		}
+		if req.GetQuery() != "" {
+			params.Search = gocloak.StringP(req.GetQuery())
+		}
+		if req.GetUsername() != "" {
+			params.Username = gocloak.StringP(req.GetUsername())
+		}
+		if req.GetEmail() != "" {
+			params.Email = gocloak.StringP(req.GetEmail())
+		}
+		if exact {
+			params.Exact = gocloak.BoolP(true)
+		}
+		if req.GetEmailDomain() != "" {
+			params.Email = gocloak.StringP("@" + req.GetEmailDomain())
+		}
+		if users, total, err = s.cloakHelper.ListUsers(ctx, params); err != nil {
+			return nil, utils.KeycloakError(ctx, err)
+		}
+	}
+
+	resp := &oauth.ListUsersResponse{}
+	if len(users) == max && (total < 0 || first+max < total) {
+		resp.NextPageToken = utils.EncodePageToken(first+max, max, filters...)
+	}
+
+	// Keycloak matches the email as a substring, so "@example.com" also matches "@example.com.au".
+	// Filtering here can shorten the page and makes Keycloak's count an upper bound.
+	suffix := "@" + strings.ToLower(req.GetEmailDomain())
+	for _, user := range users {
+		if req.GetEmailDomain() != "" && !strings.HasSuffix(strings.ToLower(safeStr(user.Email)), suffix) {
+			continue
+		}
+		resp.Users = append(resp.Users, s.profiles.PublicUser(identity, user))
+	}
+	if total >= 0 && req.GetEmailDomain() == "" {
+		resp.TotalSize = proto.Int32(int32(total))
+	}
+
+	return resp, nil
+}
+
+func (s *AuthService) BatchGetUsers(ctx context.Context, req *oauth.BatchGetUsersRequest) (*oauth.BatchGetUsersResponse, error) {
+	identity, err := utils.GetIdentity(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	userIds := req.GetUserIds()
+	if len(userIds) > maxBatchGetUsers {
+		return nil, status.Errorf(codes.InvalidArgument, "at most %d user ids per batch", maxBatchGetUsers)
+	}
+	for _, userId := range userIds {
+		if userId == "" {
+			return nil, status.Error(codes.InvalidArgument, "user ids must not be empty")
+		}
+	}
+
+	logger := utils.GetLogger(ctx)
+	logger.Infof("[%s, %v] requests to view %d user profiles", identity.UserID, identity.Roles(), len(userIds))
+
+	users, err := s.userCache.GetUserProfiles(ctx, userIds)
+	if err != nil {
+		return nil, utils.KeycloakError(ctx, err)
+	}
+
+	results := make([]*oauth.BatchGetUsersResult, 0, len(userIds))
+	for _, userId := range userIds {
+		result := &oauth.BatchGetUsersResult{UserId: userId}
+		if user, ok := users[userId]; ok {
+			result.User = s.profiles.PublicUser(identity, user)
+		} else {
+			result.NotFound = true
+		}
+		results = append(results, result)
+	}
+
+	return &oauth.BatchGetUsersResponse{Results: results}, nil
+}
+
+func (s *AuthService) GetMe(ctx context.Context, req *oauth.GetMeRequest) (*oauth.GetMeResponse, error) {
+	identity, err := utils.GetIdentity(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		user        *gocloak.User
+		groups      []*gocloak.Group
+		clientRoles map[string][]string
+	)
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.Go(func() (err error) {
+		// Don't go through the cache, email_verified and required_actions must be current
+		user, err = s.cloakHelper.GetUserProfile(groupCtx, identity.UserID)
+		return err
+	})
+	group.Go(func() (err error) {
+		groups, err = s.cloakHelper.GetUserGroups(groupCtx, identity.UserID)
+		return err
+	})
+	group.Go(func() (err error) {
+		clientRoles, err = s.cloakHelper.GetEffectiveClientRoles(groupCtx, identity.UserID)
+		return err
+	})
+	if err := group.Wait(); err != nil {
+		return nil, utils.KeycloakError(ctx, err)
+	}
+
+	me := &oauth.Me{
+		User:          s.profiles.PublicUser(identity, user),
+		ClientRoles:   make(map[string]*oauth.ClientRoles, len(clientRoles)),
+		EmailVerified: user.EmailVerified != nil && *user.EmailVerified,
+	}
+	for client, roles := range clientRoles {
+		me.ClientRoles[client] = &oauth.ClientRoles{Roles: roles}
+	}
+	for _, g := range groups {
+		me.Groups = append(me.Groups, &oauth.Group{
+			Id:   safeStr(g.ID),
+			Name: safeStr(g.Name),
+			Path: safeStr(g.Path),
+		})
+	}
+	if user.RequiredActions != nil {
+		me.RequiredActions = *user.RequiredActions
+	}
+	if user.CreatedTimestamp != nil {
+		me.CreatedAt = timestamppb.New(time.UnixMilli(*user.CreatedTimestamp))
+	}
+
+	return &oauth.GetMeResponse{Me: me}, nil
+}
+
+func (s *AuthService) UpdateMe(ctx context.Context, req *oauth.UpdateMeRequest) (*oauth.UpdateMeResponse, error) {
+	identity, err := utils.GetIdentity(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := req.GetUpdateMask().GetPaths()
+	if len(paths) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "update_mask is required")
+	}
+	update := req.GetUser()
+	for _, path := range paths {
+		var err error
+		switch path {
+		case "firstname":
+			err = utils.ValidateName(path, update.GetFirstname())
+		case "lastname":
+			err = utils.ValidateName(path, update.GetLastname())
+		case "email":
+			err = utils.ValidateEmail(path, update.GetEmail())
+		default:
+			err = fmt.Errorf("%s can't be updated", path)
+		}
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	logger := utils.GetLogger(ctx)
+	logger.Infof("[%s] updates own profile fields %v", identity.UserID, paths)
+
+	// Start from the current representation so Keycloak keeps attributes and required actions.
+	// Don't go through the cache, cached users are shared.
+	current, err := s.cloakHelper.GetUserProfile(ctx, identity.UserID)
+	if err != nil {
+		return nil, utils.KeycloakError(ctx, err)
+	}
+	user := *current
+	emailChanged := false
+	for _, path := range paths {
+		switch path {
+		case "firstname":
+			user.FirstName = gocloak.StringP(update.GetFirstname())
+		case "lastname":
+			user.LastName = gocloak.StringP(update.GetLastname())
+		case "email":
+			if strings.EqualFold(safeStr(current.Email), update.GetEmail()) {
+				continue
+			}
+			emailChanged = true
+			user.Email = gocloak.StringP(update.GetEmail())
+			user.EmailVerified = gocloak.BoolP(false)
+			// Falls back to verifying on next login if the email can't be sent
+			var actions []string
+			if current.RequiredActions != nil {
+				actions = slices.Clone(*current.RequiredActions)
+			}
+			if !slices.Contains(actions, requiredActionVerifyEmail) {
+				actions = append(actions, requiredActionVerifyEmail)
+			}
+			user.RequiredActions = &actions
+		}
+	}
+
+	if err := s.cloakHelper.UpdateUser(ctx, user); err != nil {
+		return nil, utils.KeycloakError(ctx, err)
+	}
+	s.userCache.Invalidate(identity.UserID)
+
+	resp := &oauth.UpdateMeResponse{User: s.profiles.PublicUser(identity, &user)}
+	if emailChanged {
+		if err := s.cloakHelper.SendVerifyEmail(ctx, identity.UserID); err != nil {
+			logger.WithError(err).Warn("failed to send verification email, the user verifies on next login")
+		} else {
+			resp.VerificationEmailSent = true
+		}
+	}
+
+	return resp, nil
+}
+
+func (s *AuthService) InvalidateUserCache(ctx context.Context, req *oauth.InvalidateUserCacheRequest) (*oauth.InvalidateUserCacheResponse, error) {
+	identity, err := utils.GetIdentity(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	logger := utils.GetLogger(ctx)
+	switch {
+	case req.GetAll():
+		logger.Infof("[%s] flushes the user cache", identity.UserID)
+		s.userCache.Flush()
+	case req.GetUserId() != "":
+		logger.Infof("[%s] evicts user %s from the user cache", identity.UserID, req.GetUserId())
+		s.userCache.Invalidate(req.GetUserId())
+	default:
+		return nil, status.Error(codes.InvalidArgument, "either user_id or all is required")
+	}
+
+	stats := s.userCache.Stats()
+	return &oauth.InvalidateUserCacheResponse{
+		Stats: &oauth.UserCacheStats{
+			Hits:   stats.Hits,
+			Misses: stats.Misses,
+			Size:   int64(stats.Size),
 		},
 	}, nil
 }
 
+// pagination returns Keycloak's first/max for a page size and page token of a list RPC
+func pagination(pageSize int32, pageToken string, filters ...string) (first, max int, err error) {
+	if pageSize < 0 {
+		return 0, 0, status.Error(codes.InvalidArgument, "page_size must not be negative")
+	}
+	max = min(int(pageSize), maxPageSize)
+
+	if pageToken != "" {
+		var tokenMax int
+		if first, tokenMax, err = utils.DecodePageToken(pageToken, filters...); err != nil {
+			return 0, 0, status.Error(codes.InvalidArgument, err.Error())
+		}
+		if max == 0 {
+			// Tokens aren't signed, a forged one must not lift the limit
+			max = min(tokenMax, maxPageSize)
+		}
+	}
+	if max == 0 {
+		max = defaultPageSize
+	}
+	return first, max, nil
+}
+
 // Helper to safely dereference string pointers
 func safeStr(s *string) string {
 	if s == nil {
@@ -0,0 +1,22 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/omnsight/omnauth/src/utils"
+)
+
+func TestPaginationClampsPageSize(t *testing.T) {
+	_, max, err := pagination(0, utils.EncodePageToken(100, 100000, "alice"), "alice")
+	if err != nil || max != maxPageSize {
+		t.Errorf("expected the page size of a token to be clamped to %d, got %d: %v", maxPageSize, max, err)
+	}
+
+	first, max, err := pagination(0, utils.EncodePageToken(40, 20, "alice"), "alice")
+	if err != nil || first != 40 || max != 20 {
+		t.Errorf("expected the token's page 40/20, got %d/%d: %v", first, max, err)
+	}
+	if _, max, _ := pagination(1000, "", "alice"); max != maxPageSize {
+		t.Errorf("expected page_size to be clamped to %d, got %d", maxPageSize, max)
+	}
+}
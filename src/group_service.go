@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+
+	"github.com/Nerzal/gocloak/v13"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/omnsight/omnauth/gen/oauth/v1"
+	"github.com/omnsight/omnauth/src/utils"
+)
+
+func (s *AuthService) ListGroups(ctx context.Context, req *oauth.ListGroupsRequest) (*oauth.ListGroupsResponse, error) {
+	first, max, err := pagination(req.GetPageSize(), req.GetPageToken(), req.GetQuery())
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := s.cloakHelper.ListGroups(ctx, req.GetQuery(), first, max)
+	if err != nil {
+		return nil, utils.KeycloakError(ctx, err)
+	}
+
+	resp := &oauth.ListGroupsResponse{}
+	for _, group := range groups {
+		resp.Groups = appendGroup(resp.Groups, group)
+	}
+	if len(groups) == max {
+		resp.NextPageToken = utils.EncodePageToken(first+max, max, req.GetQuery())
+	}
+	return resp, nil
+}
+
+// appendGroup appends group followed by its subgroups
+func appendGroup(groups []*oauth.Group, group *gocloak.Group) []*oauth.Group {
+	groups = append(groups, &oauth.Group{
+		Id:   safeStr(group.ID),
+		Name: safeStr(group.Name),
+		Path: safeStr(group.Path),
+	})
+	if group.SubGroups != nil {
+		for i := range *group.SubGroups {
+			groups = appendGroup(groups, &(*group.SubGroups)[i])
+		}
+	}
+	return groups
+}
+
+func (s *AuthService) ListGroupMembers(ctx context.Context, req *oauth.ListGroupMembersRequest) (*oauth.ListGroupMembersResponse, error) {
+	identity, err := utils.GetIdentity(ctx)
+	if err != nil {
+		return nil, err
+	}
+	first, max, err := pagination(req.GetPageSize(), req.GetPageToken(), req.GetGroup())
+	if err != nil {
+		return nil, err
+	}
+	group, err := s.resolveGroup(ctx, req.GetGroup())
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := s.cloakHelper.GetGroupMembers(ctx, safeStr(group.ID), first, max)
+	if err != nil {
+		return nil, utils.KeycloakError(ctx, err)
+	}
+
+	resp := &oauth.ListGroupMembersResponse{}
+	for _, user := range users {
+		resp.Users = append(resp.Users, s.profiles.PublicUser(identity, user))
+	}
+	if len(users) == max {
+		resp.NextPageToken = utils.EncodePageToken(first+max, max, req.GetGroup())
+	}
+	return resp, nil
+}
+
+func (s *AuthService) AddUserToGroup(ctx context.Context, req *oauth.AddUserToGroupRequest) (resp *oauth.AddUserToGroupResponse, err error) {
+	defer func() {
+		utils.Audit(ctx, s.audit, utils.AuditAddGroupMember, req.GetUserId(), map[string]string{"group": req.GetGroup()}, err)
+	}()
+
+	if req.GetUserId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+	group, err := s.resolveGroup(ctx, req.GetGroup())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.cloakHelper.AddUserToGroup(ctx, req.GetUserId(), safeStr(group.ID)); err != nil {
+		return nil, utils.KeycloakError(ctx, err)
+	}
+	return &oauth.AddUserToGroupResponse{}, nil
+}
+
+func (s *AuthService) RemoveUserFromGroup(ctx context.Context, req *oauth.RemoveUserFromGroupRequest) (resp *oauth.RemoveUserFromGroupResponse, err error) {
+	defer func() {
+		utils.Audit(ctx, s.audit, utils.AuditRemoveGroupMember, req.GetUserId(), map[string]string{"group": req.GetGroup()}, err)
+	}()
+
+	// Admins could drop the group granting their own admin role
+	if _, err := s.otherUser(ctx, req.GetUserId()); err != nil {
+		return nil, err
+	}
+	group, err := s.resolveGroup(ctx, req.GetGroup())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.cloakHelper.RemoveUserFromGroup(ctx, req.GetUserId(), safeStr(group.ID)); err != nil {
+		return nil, utils.KeycloakError(ctx, err)
+	}
+	return &oauth.RemoveUserFromGroupResponse{}, nil
+}
+
+// resolveGroup looks up a group by path
+func (s *AuthService) resolveGroup(ctx context.Context, path string) (*gocloak.Group, error) {
+	if path == "" {
+		return nil, status.Error(codes.InvalidArgument, "group is required")
+	}
+	group, err := s.cloakHelper.GetGroupByPath(ctx, path)
+	if err != nil {
+		return nil, utils.KeycloakError(ctx, err)
+	}
+	return group, nil
+}
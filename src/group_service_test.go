@@ -0,0 +1,28 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/omnsight/omnauth/gen/oauth/v1"
+	"github.com/omnsight/omnauth/src/utils"
+)
+
+func TestRemoveUserFromGroupRejectsCaller(t *testing.T) {
+	kc := newFakeUserAdmin(t)
+	kc.joinGroup("admin-1", "admins")
+	s := kc.newService(t)
+	admin := utils.WithIdentity(context.Background(), utils.NewIdentity(jwt.MapClaims{"sub": "admin-1"}, "omniauth"))
+
+	_, err := s.RemoveUserFromGroup(admin, &oauth.RemoveUserFromGroupRequest{UserId: "admin-1", Group: "admins"})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected FailedPrecondition, got %v", err)
+	}
+	if !kc.members["admins"]["admin-1"] {
+		t.Error("expected the caller to stay in the group")
+	}
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/Nerzal/gocloak/v13"
+	"github.com/sirupsen/logrus"
+
+	"github.com/omnsight/omnauth/src/utils"
+)
+
+const (
+	defaultTierExpiryInterval        = 5 * time.Minute
+	defaultUnverifiedCleanupInterval = time.Hour
+	// jobPageSize is how many users a job fetches from Keycloak at once
+	jobPageSize = 100
+	// maxUnverifiedDeletions bounds the deletions of one cleanup run, the rest waits for the next run
+	maxUnverifiedDeletions = 100
+)
+
+// NewJobRunner configures the background jobs from the environment
+func NewJobRunner(s *AuthService, locker utils.Locker) (*utils.JobRunner, error) {
+	runner := utils.NewJobRunner(locker)
+
+	interval, err := utils.DurationEnv(utils.TierExpiryInterval, defaultTierExpiryInterval)
+	if err != nil {
+		return nil, err
+	}
+	err = runner.Add(utils.Job{
+		Name:     "tier-expiry",
+		Interval: interval,
+		Jitter:   interval / 10,
+		Run:      s.DemoteExpiredTiers,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", utils.TierExpiryInterval, err)
+	}
+
+	maxAge, err := utils.DurationEnv(utils.UnverifiedUserMaxAge, 0)
+	if err != nil {
+		return nil, err
+	}
+	if maxAge > 0 {
+		interval, err := utils.DurationEnv(utils.UnverifiedCleanupInterval, defaultUnverifiedCleanupInterval)
+		if err != nil {
+			return nil, err
+		}
+		err = runner.Add(utils.Job{
+			Name:     "unverified-cleanup",
+			Interval: interval,
+			Jitter:   interval / 10,
+			Run: func(ctx context.Context) error {
+				return s.DeleteUnverifiedUsers(ctx, maxAge)
+			},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", utils.UnverifiedCleanupInterval, err)
+		}
+	}
+
+	return runner, nil
+}
+
+// jobsEnabled reports whether the background jobs should run
+func jobsEnabled() bool {
+	return os.Getenv(utils.JobsEnabled) != "false"
+}
+
+// DemoteExpiredTiers moves users whose tier expired back to the default tier
+func (s *AuthService) DemoteExpiredTiers(ctx context.Context) error {
+	logger := utils.GetLogger(ctx)
+	now := time.Now()
+
+	var expired []*gocloak.User
+	for tier, path := range s.tiers.Groups {
+		if tier == s.tiers.Default {
+			continue
+		}
+		group, err := s.resolveGroup(ctx, path)
+		if err != nil {
+			return err
+		}
+		err = forEachPage(func(first, max int) ([]*gocloak.User, error) {
+			return s.cloakHelper.GetGroupMembers(ctx, safeStr(group.ID), first, max)
+		}, func(user *gocloak.User) {
+			value := utils.UserAttribute(user, utils.TierExpiresAtAttribute)
+			if value == "" {
+				return
+			}
+			expiresAt, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				logger.WithError(err).Warnf("ignoring invalid %s of user %s", utils.TierExpiresAtAttribute, safeStr(user.ID))
+				return
+			}
+			if expiresAt.Before(now) {
+				expired = append(expired, user)
+			}
+		})
+		if err != nil {
+			return utils.KeycloakError(ctx, err)
+		}
+	}
+
+	failed := 0
+	for _, user := range expired {
+		userId := safeStr(user.ID)
+		_, err := s.moveToTier(ctx, userId, s.tiers.Default)
+		if err == nil {
+			err = s.updateUser(ctx, userId, func(user *gocloak.User) {
+				setAttribute(user, utils.TierExpiresAtAttribute, "")
+			})
+		}
+		utils.Audit(ctx, s.audit, utils.AuditExpireTier, userId, map[string]string{"tier": s.tiers.Default}, err)
+		if err != nil {
+			failed++
+		}
+	}
+
+	logger.WithFields(logrus.Fields{"expired": len(expired), "failed": failed}).Info("demoted expired tiers")
+	if failed > 0 {
+		return fmt.Errorf("failed to demote %d of %d users", failed, len(expired))
+	}
+	return nil
+}
+
+// DeleteUnverifiedUsers deletes users who registered more than maxAge ago and never verified their email.
+// Users created by admins with CreateUser and users without an email, e.g. service accounts, are kept.
+func (s *AuthService) DeleteUnverifiedUsers(ctx context.Context, maxAge time.Duration) error {
+	logger := utils.GetLogger(ctx)
+	cutoff := time.Now().Add(-maxAge).UnixMilli()
+
+	var stale []*gocloak.User
+	err := forEachPage(func(first, max int) ([]*gocloak.User, error) {
+		users, _, err := s.cloakHelper.ListUsers(ctx, gocloak.GetUsersParams{
+			EmailVerified: gocloak.BoolP(false),
+			First:         gocloak.IntP(first),
+			Max:           gocloak.IntP(max),
+		})
+		return users, err
+	}, func(user *gocloak.User) {
+		switch {
+		case len(stale) >= maxUnverifiedDeletions:
+		case safeStr(user.Email) == "" || user.ServiceAccountClientID != nil:
+		case utils.UserAttribute(user, createdByAttribute) != "":
+		case user.EmailVerified != nil && *user.EmailVerified:
+		case user.CreatedTimestamp == nil || *user.CreatedTimestamp > cutoff:
+		default:
+			stale = append(stale, user)
+		}
+	})
+	if err != nil {
+		return utils.KeycloakError(ctx, err)
+	}
+
+	failed := 0
+	for _, user := range stale {
+		userId := safeStr(user.ID)
+		err := s.cloakHelper.DeleteUser(ctx, userId)
+		if err == nil {
+			s.userCache.Invalidate(userId)
+		}
+		utils.Audit(ctx, s.audit, utils.AuditDeleteUser, userId, map[string]string{
+			"username": safeStr(user.Username),
+			"reason":   "email unverified for " + maxAge.String(),
+		}, err)
+		if err != nil {
+			failed++
+		}
+	}
+
+	logger.WithFields(logrus.Fields{"deleted": len(stale) - failed, "failed": failed}).Info("cleaned up unverified users")
+	if failed > 0 {
+		return fmt.Errorf("failed to delete %d of %d unverified users", failed, len(stale))
+	}
+	return nil
+}
+
+// forEachPage calls visit for every user of every page returned by fetch
+func forEachPage(fetch func(first, max int) ([]*gocloak.User, error), visit func(user *gocloak.User)) error {
+	for first := 0; ; first += jobPageSize {
+		users, err := fetch(first, jobPageSize)
+		if err != nil {
+			return err
+		}
+		for _, user := range users {
+			visit(user)
+		}
+		if len(users) < jobPageSize {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,279 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Nerzal/gocloak/v13"
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/omnsight/omnauth/gen/oauth/v1"
+	"github.com/omnsight/omnauth/src/utils"
+)
+
+const jobsRealm = "test"
+
+// fakeUserAdmin serves the client-credentials token endpoint, the users API and group membership
+type fakeUserAdmin struct {
+	server *httptest.Server
+
+	mu        sync.Mutex
+	users     map[string]gocloak.User
+	deleted   []string
+	loggedOut []string
+	// members maps a group path to the IDs of its members, groups have the ID "id-" + path
+	members map[string]map[string]bool
+}
+
+func newFakeUserAdmin(t *testing.T) *fakeUserAdmin {
+	t.Helper()
+	kc := &fakeUserAdmin{users: make(map[string]gocloak.User), members: make(map[string]map[string]bool)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /realms/"+jobsRealm+"/protocol/openid-connect/token", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]interface{}{"access_token": "token", "expires_in": 300})
+	})
+	mux.HandleFunc("POST /admin/realms/"+jobsRealm+"/users", func(w http.ResponseWriter, r *http.Request) {
+		var user gocloak.User
+		json.NewDecoder(r.Body).Decode(&user)
+		kc.mu.Lock()
+		defer kc.mu.Unlock()
+		// Created users are backdated so the cleanup considers them
+		id := "created-" + strconv.Itoa(len(kc.users))
+		user.ID = gocloak.StringP(id)
+		user.CreatedTimestamp = gocloak.Int64P(time.Now().Add(-48 * time.Hour).UnixMilli())
+		kc.users[id] = user
+		w.Header().Set("Location", kc.server.URL+r.URL.Path+"/"+id)
+		w.WriteHeader(http.StatusCreated)
+	})
+	mux.HandleFunc("GET /admin/realms/"+jobsRealm+"/users", func(w http.ResponseWriter, r *http.Request) {
+		users := kc.search(r.URL.Query())
+		first, _ := strconv.Atoi(r.URL.Query().Get("first"))
+		max, _ := strconv.Atoi(r.URL.Query().Get("max"))
+		users = users[min(first, len(users)):]
+		writeJSON(w, http.StatusOK, users[:min(max, len(users))])
+	})
+	mux.HandleFunc("GET /admin/realms/"+jobsRealm+"/users/count", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, len(kc.search(r.URL.Query())))
+	})
+	mux.HandleFunc("GET /admin/realms/"+jobsRealm+"/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		kc.mu.Lock()
+		defer kc.mu.Unlock()
+		user, ok := kc.users[r.PathValue("id")]
+		if !ok {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "User not found"})
+			return
+		}
+		writeJSON(w, http.StatusOK, user)
+	})
+	mux.HandleFunc("PUT /admin/realms/"+jobsRealm+"/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		var user gocloak.User
+		json.NewDecoder(r.Body).Decode(&user)
+		kc.mu.Lock()
+		defer kc.mu.Unlock()
+		kc.users[r.PathValue("id")] = user
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("GET /admin/realms/"+jobsRealm+"/users/{id}/groups", func(w http.ResponseWriter, r *http.Request) {
+		kc.mu.Lock()
+		defer kc.mu.Unlock()
+		groups := []gocloak.Group{}
+		for path, members := range kc.members {
+			if members[r.PathValue("id")] {
+				groups = append(groups, gocloak.Group{ID: gocloak.StringP("id-" + path), Path: gocloak.StringP("/" + path)})
+			}
+		}
+		writeJSON(w, http.StatusOK, groups)
+	})
+	mux.HandleFunc("PUT /admin/realms/"+jobsRealm+"/users/{id}/groups/{group}", func(w http.ResponseWriter, r *http.Request) {
+		kc.joinGroup(r.PathValue("id"), strings.TrimPrefix(r.PathValue("group"), "id-"))
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("DELETE /admin/realms/"+jobsRealm+"/users/{id}/groups/{group}", func(w http.ResponseWriter, r *http.Request) {
+		kc.mu.Lock()
+		defer kc.mu.Unlock()
+		delete(kc.members[strings.TrimPrefix(r.PathValue("group"), "id-")], r.PathValue("id"))
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("GET /admin/realms/"+jobsRealm+"/group-by-path/{path...}", func(w http.ResponseWriter, r *http.Request) {
+		path := r.PathValue("path")
+		writeJSON(w, http.StatusOK, gocloak.Group{ID: gocloak.StringP("id-" + path), Path: gocloak.StringP("/" + path)})
+	})
+	mux.HandleFunc("GET /admin/realms/"+jobsRealm+"/groups/{group}/members", func(w http.ResponseWriter, r *http.Request) {
+		kc.mu.Lock()
+		users := []gocloak.User{}
+		for id := range kc.members[strings.TrimPrefix(r.PathValue("group"), "id-")] {
+			users = append(users, kc.users[id])
+		}
+		kc.mu.Unlock()
+		sort.Slice(users, func(i, j int) bool { return *users[i].ID < *users[j].ID })
+		first, _ := strconv.Atoi(r.URL.Query().Get("first"))
+		max, _ := strconv.Atoi(r.URL.Query().Get("max"))
+		users = users[min(first, len(users)):]
+		writeJSON(w, http.StatusOK, users[:min(max, len(users))])
+	})
+	mux.HandleFunc("POST /admin/realms/"+jobsRealm+"/users/{id}/logout", func(w http.ResponseWriter, r *http.Request) {
+		kc.mu.Lock()
+		defer kc.mu.Unlock()
+		kc.loggedOut = append(kc.loggedOut, r.PathValue("id"))
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("DELETE /admin/realms/"+jobsRealm+"/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		kc.mu.Lock()
+		defer kc.mu.Unlock()
+		delete(kc.users, r.PathValue("id"))
+		kc.deleted = append(kc.deleted, r.PathValue("id"))
+		w.WriteHeader(http.StatusNoContent)
+	})
+	kc.server = httptest.NewServer(mux)
+	t.Cleanup(kc.server.Close)
+	return kc
+}
+
+func (kc *fakeUserAdmin) addUser(id, email string, verified bool, age time.Duration) {
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+	user := gocloak.User{
+		ID:               gocloak.StringP(id),
+		Username:         gocloak.StringP(id),
+		EmailVerified:    gocloak.BoolP(verified),
+		CreatedTimestamp: gocloak.Int64P(time.Now().Add(-age).UnixMilli()),
+	}
+	if email != "" {
+		user.Email = gocloak.StringP(email)
+	}
+	kc.users[id] = user
+}
+
+func (kc *fakeUserAdmin) joinGroup(userID, path string) {
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+	if kc.members[path] == nil {
+		kc.members[path] = make(map[string]bool)
+	}
+	kc.members[path][userID] = true
+}
+
+// search mimics Keycloak's emailVerified filter, sorted by ID
+func (kc *fakeUserAdmin) search(query url.Values) []gocloak.User {
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+
+	users := []gocloak.User{}
+	for _, user := range kc.users {
+		verified := user.EmailVerified != nil && *user.EmailVerified
+		if query.Get("emailVerified") == "" || query.Get("emailVerified") == strconv.FormatBool(verified) {
+			users = append(users, user)
+		}
+	}
+	sort.Slice(users, func(i, j int) bool { return *users[i].ID < *users[j].ID })
+	return users
+}
+
+func (kc *fakeUserAdmin) newService(t *testing.T) *AuthService {
+	t.Helper()
+	s, err := NewAuthService(&utils.CloakHelper{
+		Client:       gocloak.NewClient(kc.server.URL),
+		URL:          kc.server.URL,
+		Realm:        jobsRealm,
+		ClientID:     "omniauth",
+		ClientSecret: "secret",
+	}, utils.NewTokenDenylistWithTTL(time.Hour))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return s
+}
+
+func writeJSON(w http.ResponseWriter, code int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(body)
+}
+
+func TestDeleteUnverifiedUsers(t *testing.T) {
+	kc := newFakeUserAdmin(t)
+	kc.addUser("stale", "stale@example.com", false, 48*time.Hour)
+	kc.addUser("recent", "recent@example.com", false, time.Hour)
+	kc.addUser("verified", "verified@example.com", true, 48*time.Hour)
+	kc.addUser("no-email", "", false, 48*time.Hour)
+	s := kc.newService(t)
+
+	// Admins provision users without verifying their email
+	admin := utils.WithIdentity(context.Background(), utils.NewIdentity(jwt.MapClaims{"sub": "admin-1"}, "omniauth"))
+	_, err := s.CreateUser(admin, &oauth.CreateUserRequest{Username: "provisioned", Email: "provisioned@example.com"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := s.DeleteUnverifiedUsers(context.Background(), 24*time.Hour); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(kc.deleted) != 1 || kc.deleted[0] != "stale" {
+		t.Errorf("expected only the stale user to be deleted, got %v", kc.deleted)
+	}
+	if _, ok := kc.users["created-4"]; !ok {
+		t.Error("expected the user created by an admin to be kept")
+	}
+}
+
+func TestNewJobRunnerRejectsNonPositiveIntervals(t *testing.T) {
+	s := newFakeUserAdmin(t).newService(t)
+	for key, value := range map[string]string{utils.TierExpiryInterval: "0s", utils.UnverifiedCleanupInterval: "-1h"} {
+		t.Run(key, func(t *testing.T) {
+			t.Setenv(utils.UnverifiedUserMaxAge, "720h")
+			t.Setenv(key, value)
+			if _, err := NewJobRunner(s, utils.NewMemoryLocker()); err == nil {
+				t.Errorf("expected %s=%s to be rejected", key, value)
+			}
+		})
+	}
+}
+
+func TestDemoteExpiredTiers(t *testing.T) {
+	kc := newFakeUserAdmin(t)
+	s := kc.newService(t)
+
+	// More members than fit on one page, every other tier expired
+	expired := map[string]bool{}
+	for i := 0; i < jobPageSize+20; i++ {
+		id := fmt.Sprintf("pro-%03d", i)
+		kc.addUser(id, "", true, time.Hour)
+		kc.joinGroup(id, "pro-group")
+		expiresAt := time.Now().Add(time.Hour)
+		if i%2 == 0 {
+			expiresAt = time.Now().Add(-time.Hour)
+			expired[id] = true
+		}
+		kc.mu.Lock()
+		user := kc.users[id]
+		user.Attributes = &map[string][]string{utils.TierExpiresAtAttribute: {expiresAt.Format(time.RFC3339)}}
+		kc.users[id] = user
+		kc.mu.Unlock()
+	}
+
+	if err := s.DemoteExpiredTiers(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i := 0; i < jobPageSize+20; i++ {
+		id := fmt.Sprintf("pro-%03d", i)
+		user := kc.users[id]
+		attribute := utils.UserAttribute(&user, utils.TierExpiresAtAttribute)
+		switch {
+		case expired[id] && (kc.members["pro-group"][id] || !kc.members["default-group"][id] || attribute != ""):
+			t.Errorf("expected %s to be moved to the default tier", id)
+		case !expired[id] && (!kc.members["pro-group"][id] || kc.members["default-group"][id] || attribute == ""):
+			t.Errorf("expected %s to keep its tier", id)
+		}
+	}
+}
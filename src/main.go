@@ -5,12 +5,15 @@ import (
 	"net"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
 
 	gwRuntime "github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
@@ -36,15 +39,65 @@ func main() {
 		logrus.Fatalf("missing environment variable %s", utils.KeycloakClientID)
 	}
 
+	cloakHelper := utils.NewCloakHelper()
+
+	// Verify token signatures against the realm keys unless a trusted gateway is explicitly configured
+	jwksVerifier := utils.NewJWKSVerifier(cloakHelper)
+	var verifier utils.TokenVerifier = jwksVerifier
+	if os.Getenv(utils.AuthTrustedGateway) == "true" {
+		logrus.Warnf("%s is set, token signatures will NOT be verified", utils.AuthTrustedGateway)
+		verifier = utils.TrustedGatewayVerifier{}
+	}
+
+	validator, err := utils.NewClaimsValidator(cloakHelper)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"error": err,
+		}).Fatal("invalid token validation config")
+	}
+	// Revoked sessions are denied right away, without waiting for their tokens to expire
+	denylist, err := utils.NewTokenDenylist()
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"error": err,
+		}).Fatal("invalid token denylist config")
+	}
+	authenticator := &utils.Authenticator{
+		ClientID:  clientId,
+		Verifier:  verifier,
+		Validator: validator,
+		Denylist:  denylist,
+	}
+	if methods := os.Getenv(utils.AuthIntrospectMethods); methods != "" {
+		introspector, err := utils.NewIntrospectionVerifier(cloakHelper)
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"error": err,
+			}).Fatal("invalid token introspection config")
+		}
+		authenticator.Introspector = introspector
+		authenticator.IntrospectMethods = utils.MethodSet(methods)
+	}
+
+	// Policies are loaded from the method descriptors once every service is registered
+	authorizer := utils.NewAuthorizer()
+
 	// Create a gRPC server
 	gRPCServer := grpc.NewServer(
-		grpc.ChainUnaryInterceptor(utils.LoggingInterceptor, utils.GrpcGatewayIdentityInterceptor(clientId)),
+		grpc.ChainUnaryInterceptor(
+			utils.LoggingInterceptor,
+			utils.GrpcGatewayIdentityInterceptor(authenticator),
+			utils.AuthorizationInterceptor(authorizer),
+		),
+		grpc.ChainStreamInterceptor(
+			utils.LoggingStreamInterceptor,
+			utils.GrpcGatewayIdentityStreamInterceptor(authenticator),
+			utils.AuthorizationStreamInterceptor(authorizer),
+		),
 	)
 
-	cloakHelper := utils.NewCloakHelper()
-
 	// Register your business logic implementation with the gRPC server
-	authService, err := NewAuthService(cloakHelper)
+	authService, err := NewAuthService(cloakHelper, denylist)
 	if err != nil {
 		logrus.WithFields(logrus.Fields{
 			"error": err,
@@ -52,8 +105,49 @@ func main() {
 	}
 	oauth.RegisterAuthServiceServer(gRPCServer, authService)
 
-	// Enable reflection for debugging
-	reflection.Register(gRPCServer)
+	// Health checks are always served and never require a token
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(gRPCServer, healthServer)
+
+	// Reflection exposes the whole API surface, only serve it when explicitly enabled
+	if os.Getenv(utils.GrpcReflection) == "true" {
+		reflection.Register(gRPCServer)
+	}
+
+	services := gRPCServer.GetServiceInfo()
+	publicPolicy := &oauth.AuthPolicy{Public: true}
+	for serviceName, info := range services {
+		if serviceName == healthpb.Health_ServiceDesc.ServiceName || strings.HasPrefix(serviceName, "grpc.reflection.") {
+			authorizer.SetServicePolicy(serviceName, info, publicPolicy)
+		}
+	}
+	for method := range utils.MethodSet(os.Getenv(utils.AuthPublicMethods)) {
+		if err := authorizer.SetPublic(method); err != nil {
+			logrus.WithFields(logrus.Fields{
+				"error": err,
+			}).Fatal("invalid " + utils.AuthPublicMethods)
+		}
+	}
+
+	// Refuse to start if any registered method has no authorization policy
+	if err := authorizer.LoadPolicies(services); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"error": err,
+		}).Fatal("incomplete authorization policies")
+	}
+	authenticator.PublicMethods = authorizer.PublicMethods()
+	authenticator.AnonymousMethods = authorizer.AnonymousMethods()
+
+	// Background jobs, each replica takes a lock before running a job
+	jobRunner, err := NewJobRunner(authService, utils.NewMemoryLocker())
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"error": err,
+		}).Fatal("failed to configure background jobs")
+	}
+	if jobsEnabled() {
+		jobRunner.Start(context.Background())
+	}
 
 	// Start the gRPC server in a separate goroutine
 	go func() {
@@ -79,7 +173,8 @@ func main() {
 
 	// Create the gRPC-Gateway's multiplexer (router)
 	// This mux knows how to translate HTTP routes (from proto definitions) to gRPC calls
-	gwmux := gwRuntime.NewServeMux()
+	// Unescape %2F too, so nested group paths fit in a single path parameter
+	gwmux := gwRuntime.NewServeMux(gwRuntime.WithUnescapingMode(gwRuntime.UnescapingModeAllCharacters))
 
 	// Register all service handlers with the gateway's router
 	if err := oauth.RegisterAuthServiceHandler(ctx, gwmux, conn); err != nil {
@@ -92,13 +187,49 @@ func main() {
 	// Create a Gin router
 	r := gin.New()
 	r.Use(gin.Recovery())
+	r.Use(utils.RequestIDMiddleware())
 	r.Use(gin.LoggerWithConfig(gin.LoggerConfig{
-		SkipPaths: []string{"/health"},
+		SkipPaths: []string{"/health", "/jobs"},
 	}))
 
+	// Browser sessions: the login flow runs on /auth/* and the session cookie authenticates /v1/*
+	gatewayHandlers := []gin.HandlerFunc{gin.WrapH(gwmux)}
+	if os.Getenv(utils.AuthCallbackURL) != "" {
+		browserAuth, err := utils.NewBrowserAuth(cloakHelper, validator.Issuer, utils.NewMemorySessionStore())
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"error": err,
+			}).Fatal("invalid browser session config")
+		}
+		// Cookie authenticated requests must pass the CSRF check, this has to run before the routes are added
+		r.Use(browserAuth.CSRFMiddleware())
+		browserAuth.Register(r)
+		gatewayHandlers = append([]gin.HandlerFunc{browserAuth.SessionMiddleware()}, gatewayHandlers...)
+	}
+
+	// Keycloak posts logout tokens here when sessions end, they are always verified against the realm keys
+	backchannelLogout := &utils.BackchannelLogout{
+		Verifier: jwksVerifier,
+		Issuer:   validator.Issuer,
+		ClientID: clientId,
+		Leeway:   validator.Leeway,
+		Denylist: denylist,
+	}
+	r.POST("/auth/backchannel-logout", backchannelLogout.Handler)
+
 	// Tell Gin to proxy any requests on /v1/* to the gRPC-Gateway
 	// THIS IS THE "CONNECTION"
-	r.Any("/v1/*any", gin.WrapH(gwmux))
+	r.Any("/v1/*any", gatewayHandlers...)
+
+	// Metrics of the background jobs are served without a token, only serve them when explicitly enabled
+	if os.Getenv(utils.JobsMetrics) == "true" {
+		r.GET("/jobs", func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{
+				"enabled": jobsEnabled(),
+				"jobs":    jobRunner.Stats(),
+			})
+		})
+	}
 
 	// Add other Gin routes as needed
 	r.GET("/health", func(c *gin.Context) {
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/Nerzal/gocloak/v13"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/omnsight/omnauth/gen/oauth/v1"
+	"github.com/omnsight/omnauth/src/utils"
+)
+
+func (s *AuthService) ListMySessions(ctx context.Context, req *oauth.ListMySessionsRequest) (*oauth.ListMySessionsResponse, error) {
+	identity, err := utils.GetIdentity(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := s.cloakHelper.GetUserSessions(ctx, identity.UserID)
+	if err != nil {
+		return nil, utils.KeycloakError(ctx, err)
+	}
+
+	return &oauth.ListMySessionsResponse{Sessions: userSessions(sessions, identity.SessionID)}, nil
+}
+
+func (s *AuthService) RevokeMySession(ctx context.Context, req *oauth.RevokeMySessionRequest) (*oauth.RevokeMySessionResponse, error) {
+	identity, err := utils.GetIdentity(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.GetSessionId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "session_id is required")
+	}
+
+	// Only sessions of the caller may be ended
+	sessions, err := s.cloakHelper.GetUserSessions(ctx, identity.UserID)
+	if err != nil {
+		return nil, utils.KeycloakError(ctx, err)
+	}
+	owned := false
+	for _, session := range sessions {
+		owned = owned || safeStr(session.ID) == req.GetSessionId()
+	}
+	if !owned {
+		return nil, status.Errorf(codes.NotFound, "session %s not found", req.GetSessionId())
+	}
+
+	if err := s.cloakHelper.LogoutSession(ctx, req.GetSessionId()); err != nil {
+		return nil, utils.KeycloakError(ctx, err)
+	}
+	s.denylist.RevokeSession(req.GetSessionId())
+
+	return &oauth.RevokeMySessionResponse{}, nil
+}
+
+func (s *AuthService) ListUserSessions(ctx context.Context, req *oauth.ListUserSessionsRequest) (*oauth.ListUserSessionsResponse, error) {
+	identity, err := utils.GetIdentity(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.GetUserId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+
+	sessions, err := s.cloakHelper.GetUserSessions(ctx, req.GetUserId())
+	if err != nil {
+		return nil, utils.KeycloakError(ctx, err)
+	}
+
+	return &oauth.ListUserSessionsResponse{Sessions: userSessions(sessions, identity.SessionID)}, nil
+}
+
+func (s *AuthService) RevokeAllUserSessions(ctx context.Context, req *oauth.RevokeAllUserSessionsRequest) (resp *oauth.RevokeAllUserSessionsResponse, err error) {
+	details := map[string]string{}
+	defer func() {
+		utils.Audit(ctx, s.audit, utils.AuditRevokeSessions, req.GetUserId(), details, err)
+	}()
+
+	if req.GetUserId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+
+	// The session IDs are needed for the denylist, Keycloak's logout doesn't return them
+	sessions, err := s.cloakHelper.GetUserSessions(ctx, req.GetUserId())
+	if err != nil {
+		return nil, utils.KeycloakError(ctx, err)
+	}
+	if err := s.cloakHelper.LogoutUser(ctx, req.GetUserId()); err != nil {
+		return nil, utils.KeycloakError(ctx, err)
+	}
+	for _, session := range sessions {
+		s.denylist.RevokeSession(safeStr(session.ID))
+	}
+	// Also deny tokens of sessions missing from the listing, e.g. ones started while revoking
+	s.denylist.RevokeSubject(req.GetUserId(), time.Now())
+	details["sessions"] = strconv.Itoa(len(sessions))
+
+	return &oauth.RevokeAllUserSessionsResponse{Revoked: int32(len(sessions))}, nil
+}
+
+// userSessions converts Keycloak sessions, marking the one with ID current
+func userSessions(sessions []*gocloak.UserSessionRepresentation, current string) []*oauth.UserSession {
+	result := make([]*oauth.UserSession, 0, len(sessions))
+	for _, session := range sessions {
+		userSession := &oauth.UserSession{
+			Id:             safeStr(session.ID),
+			IpAddress:      safeStr(session.IPAddress),
+			StartTime:      millisTimestamp(session.Start),
+			LastAccessTime: millisTimestamp(session.LastAccess),
+			Current:        current != "" && safeStr(session.ID) == current,
+		}
+		if session.Clients != nil {
+			// Keyed by the client's internal ID, the values are the client IDs
+			for _, clientID := range *session.Clients {
+				userSession.Clients = append(userSession.Clients, clientID)
+			}
+			slices.Sort(userSession.Clients)
+		}
+		result = append(result, userSession)
+	}
+	return result
+}
+
+// millisTimestamp converts Keycloak's epoch milliseconds
+func millisTimestamp(millis *int64) *timestamppb.Timestamp {
+	if millis == nil {
+		return nil
+	}
+	return timestamppb.New(time.UnixMilli(*millis))
+}
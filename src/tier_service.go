@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/Nerzal/gocloak/v13"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/omnsight/omnauth/gen/oauth/v1"
+	"github.com/omnsight/omnauth/src/utils"
+)
+
+func (s *AuthService) SetUserTier(ctx context.Context, req *oauth.SetUserTierRequest) (resp *oauth.SetUserTierResponse, err error) {
+	details := map[string]string{"tier": req.GetTier()}
+	if req.ExpiresAt != nil {
+		details["expires_at"] = req.GetExpiresAt().AsTime().Format(time.RFC3339)
+	}
+	defer func() {
+		utils.Audit(ctx, s.audit, utils.AuditSetUserTier, req.GetUserId(), details, err)
+	}()
+
+	if req.GetUserId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+	if _, ok := s.tiers.Groups[req.GetTier()]; !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "tier must be one of %v", s.tiers.Tiers())
+	}
+	var expiresAt string
+	if req.ExpiresAt != nil {
+		// The default tier is what expired tiers fall back to, it can't expire itself
+		if req.GetTier() == s.tiers.Default {
+			return nil, status.Errorf(codes.InvalidArgument, "expires_at can't be set for the default tier %s", s.tiers.Default)
+		}
+		if err := req.GetExpiresAt().CheckValid(); err != nil || !req.GetExpiresAt().AsTime().After(time.Now()) {
+			return nil, status.Error(codes.InvalidArgument, "expires_at must be in the future")
+		}
+		expiresAt = req.GetExpiresAt().AsTime().UTC().Format(time.RFC3339)
+	}
+
+	changed, err := s.moveToTier(ctx, req.GetUserId(), req.GetTier())
+	if err != nil {
+		return nil, err
+	}
+	err = s.updateUser(ctx, req.GetUserId(), func(user *gocloak.User) {
+		setAttribute(user, utils.TierExpiresAtAttribute, expiresAt)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	clientRoles, err := s.cloakHelper.GetEffectiveClientRoles(ctx, req.GetUserId())
+	if err != nil {
+		return nil, utils.KeycloakError(ctx, err)
+	}
+
+	resp = &oauth.SetUserTierResponse{
+		Tier:        req.GetTier(),
+		ExpiresAt:   req.GetExpiresAt(),
+		Changed:     changed,
+		ClientRoles: make(map[string]*oauth.ClientRoles, len(clientRoles)),
+	}
+	for client, roles := range clientRoles {
+		resp.ClientRoles[client] = &oauth.ClientRoles{Roles: roles}
+	}
+	return resp, nil
+}
+
+// moveToTier makes the user a member of the tier's group and removes them from the other tier groups.
+// Keycloak has no transactions, so the new group is added first: a failure half way leaves the user
+// in both tiers rather than in none, and the call can be retried.
+func (s *AuthService) moveToTier(ctx context.Context, userId, tier string) (bool, error) {
+	groups, err := s.cloakHelper.GetUserGroups(ctx, userId)
+	if err != nil {
+		return false, utils.KeycloakError(ctx, err)
+	}
+	memberOf := make(map[string]string, len(groups))
+	for _, group := range groups {
+		memberOf[strings.Trim(safeStr(group.Path), "/")] = safeStr(group.ID)
+	}
+
+	changed := false
+	target := s.tiers.Groups[tier]
+	if _, ok := memberOf[target]; !ok {
+		group, err := s.resolveGroup(ctx, target)
+		if err != nil {
+			return false, err
+		}
+		if err := s.cloakHelper.AddUserToGroup(ctx, userId, safeStr(group.ID)); err != nil {
+			return false, utils.KeycloakError(ctx, err)
+		}
+		changed = true
+	}
+
+	for _, path := range s.tiers.Groups {
+		groupId, ok := memberOf[path]
+		if !ok || path == target {
+			continue
+		}
+		if err := s.cloakHelper.RemoveUserFromGroup(ctx, userId, groupId); err != nil {
+			return changed, utils.KeycloakError(ctx, err)
+		}
+		changed = true
+	}
+	return changed, nil
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/omnsight/omnauth/gen/oauth/v1"
+)
+
+func TestSetUserTierRejectsExpiringDefaultTier(t *testing.T) {
+	s := newFakeUserAdmin(t).newService(t)
+
+	_, err := s.SetUserTier(context.Background(), &oauth.SetUserTierRequest{
+		UserId:    "u1",
+		Tier:      s.tiers.Default,
+		ExpiresAt: timestamppb.New(time.Now().Add(time.Hour)),
+	})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument, got %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+
+	"github.com/Nerzal/gocloak/v13"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/omnsight/omnauth/gen/oauth/v1"
+	"github.com/omnsight/omnauth/src/utils"
+)
+
+func (s *AuthService) Login(ctx context.Context, req *oauth.LoginRequest) (*oauth.LoginResponse, error) {
+	if req.GetUsername() == "" || req.GetPassword() == "" {
+		return nil, status.Error(codes.InvalidArgument, "username and password are required")
+	}
+
+	jwt, err := s.cloakHelper.PasswordLogin(ctx, req.GetUsername(), req.GetPassword())
+	if err != nil {
+		return nil, utils.TokenError(ctx, err)
+	}
+
+	return &oauth.LoginResponse{Token: tokenResponse(jwt)}, nil
+}
+
+func (s *AuthService) RefreshToken(ctx context.Context, req *oauth.RefreshTokenRequest) (*oauth.RefreshTokenResponse, error) {
+	if req.GetRefreshToken() == "" {
+		return nil, status.Error(codes.InvalidArgument, "refresh_token is required")
+	}
+
+	jwt, err := s.cloakHelper.RefreshUserToken(ctx, req.GetRefreshToken())
+	if err != nil {
+		return nil, utils.TokenError(ctx, err)
+	}
+
+	return &oauth.RefreshTokenResponse{Token: tokenResponse(jwt)}, nil
+}
+
+func (s *AuthService) Logout(ctx context.Context, req *oauth.LogoutRequest) (*oauth.LogoutResponse, error) {
+	if req.GetRefreshToken() == "" {
+		return nil, status.Error(codes.InvalidArgument, "refresh_token is required")
+	}
+
+	if err := s.cloakHelper.RevokeRefreshToken(ctx, req.GetRefreshToken()); err != nil {
+		return nil, utils.TokenError(ctx, err)
+	}
+
+	return &oauth.LogoutResponse{}, nil
+}
+
+func tokenResponse(jwt *gocloak.JWT) *oauth.Token {
+	return &oauth.Token{
+		AccessToken:      jwt.AccessToken,
+		RefreshToken:     jwt.RefreshToken,
+		IdToken:          jwt.IDToken,
+		TokenType:        jwt.TokenType,
+		ExpiresIn:        int32(jwt.ExpiresIn),
+		RefreshExpiresIn: int32(jwt.RefreshExpiresIn),
+		Scope:            jwt.Scope,
+		SessionId:        jwt.SessionState,
+	}
+}
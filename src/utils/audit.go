@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Audited actions
+const (
+	AuditCreateUser     = "user.create"
+	AuditDisableUser    = "user.disable"
+	AuditEnableUser     = "user.enable"
+	AuditDeleteUser     = "user.delete"
+	AuditSetUserTier    = "user.set_tier"
+	AuditExpireTier     = "user.expire_tier"
+	AuditRevokeSessions = "user.revoke_sessions"
+
+	AuditAddGroupMember    = "group.add_member"
+	AuditRemoveGroupMember = "group.remove_member"
+)
+
+// AuditRecord describes an administrative action
+type AuditRecord struct {
+	Time time.Time
+	// Action is one of the Audit* constants
+	Action string
+	// ActorID and ActorUsername identify the caller
+	ActorID       string
+	ActorUsername string
+	// TargetID is the user or group the action applies to
+	TargetID string
+	// Details holds action specific values, e.g. the reason of a disable
+	Details map[string]string
+	// Err is set when the action failed
+	Err error
+}
+
+// AuditSink stores audit records. Record must not fail the audited action.
+type AuditSink interface {
+	Record(ctx context.Context, record AuditRecord)
+}
+
+// LogAuditSink writes audit records to the request logger, tagged with audit=true
+type LogAuditSink struct{}
+
+func (LogAuditSink) Record(ctx context.Context, record AuditRecord) {
+	fields := logrus.Fields{
+		"audit":          true,
+		"action":         record.Action,
+		"actor_id":       record.ActorID,
+		"actor_username": record.ActorUsername,
+		"target_id":      record.TargetID,
+		"audit_time":     record.Time.UTC().Format(time.RFC3339Nano),
+	}
+	for key, value := range record.Details {
+		fields["detail_"+key] = value
+	}
+
+	logger := GetLogger(ctx).WithFields(fields)
+	if record.Err != nil {
+		logger.WithError(record.Err).Warn("audit: action failed")
+		return
+	}
+	logger.Info("audit: action succeeded")
+}
+
+// Audit records action on target by the caller in ctx
+func Audit(ctx context.Context, sink AuditSink, action, targetID string, details map[string]string, err error) {
+	record := AuditRecord{
+		Time:     time.Now(),
+		Action:   action,
+		TargetID: targetID,
+		Details:  details,
+		Err:      err,
+	}
+	// Background jobs have no caller
+	if identity, ok := IdentityFromContext(ctx); ok {
+		record.ActorID = identity.UserID
+		record.ActorUsername = identity.Username
+	}
+	sink.Record(ctx, record)
+}
@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type recordingSink struct {
+	records []AuditRecord
+}
+
+func (s *recordingSink) Record(ctx context.Context, record AuditRecord) {
+	s.records = append(s.records, record)
+}
+
+func TestAuditRecordsActor(t *testing.T) {
+	sink := &recordingSink{}
+	ctx := callerContext(jwt.MapClaims{"sub": "admin-1", "preferred_username": "root"})
+
+	Audit(ctx, sink, AuditDisableUser, "u1", map[string]string{"reason": "spam"}, nil)
+	Audit(ctx, sink, AuditDeleteUser, "u2", nil, errors.New("boom"))
+
+	if len(sink.records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(sink.records))
+	}
+	record := sink.records[0]
+	if record.ActorID != "admin-1" || record.ActorUsername != "root" || record.TargetID != "u1" || record.Details["reason"] != "spam" {
+		t.Errorf("unexpected record %+v", record)
+	}
+	if record.Time.IsZero() || sink.records[1].Err == nil {
+		t.Errorf("expected time and error to be recorded, got %+v", sink.records)
+	}
+
+	// The default sink only logs
+	LogAuditSink{}.Record(ctx, record)
+}
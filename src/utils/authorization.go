@@ -0,0 +1,196 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+
+	"github.com/omnsight/omnauth/gen/oauth/v1"
+)
+
+// Reasons attached to PermissionDenied errors
+const (
+	ReasonNoPolicy     = "NO_POLICY"
+	ReasonMissingRole  = "MISSING_ROLE"
+	ReasonMissingScope = "MISSING_SCOPE"
+)
+
+// Authorizer enforces the (oauth.v1.auth) policy declared on every RPC.
+// It denies by default: methods without a policy are rejected.
+type Authorizer struct {
+	policies map[string]*oauth.AuthPolicy
+}
+
+func NewAuthorizer() *Authorizer {
+	return &Authorizer{
+		policies: make(map[string]*oauth.AuthPolicy),
+	}
+}
+
+// SetPolicy sets the policy of a method whose descriptor we don't own, e.g. grpc.reflection.
+// It takes precedence over the method's annotation.
+func (a *Authorizer) SetPolicy(fullMethod string, policy *oauth.AuthPolicy) {
+	a.policies[fullMethod] = policy
+}
+
+// SetPublic makes a method callable without a token. It refuses methods whose annotation requires
+// roles or scopes, making those public would let any caller past their role checks.
+func (a *Authorizer) SetPublic(fullMethod string) error {
+	serviceName, methodName, ok := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	if ok {
+		if policy := methodPolicy(serviceName, methodName); len(policy.GetRoles()) > 0 || len(policy.GetScopes()) > 0 {
+			return fmt.Errorf("%s requires roles %v and scopes %v, it can't be made public", fullMethod, policy.GetRoles(), policy.GetScopes())
+		}
+	}
+	a.SetPolicy(fullMethod, &oauth.AuthPolicy{Public: true})
+	return nil
+}
+
+// SetServicePolicy sets the policy of every method of a registered service
+func (a *Authorizer) SetServicePolicy(serviceName string, info grpc.ServiceInfo, policy *oauth.AuthPolicy) {
+	for _, method := range info.Methods {
+		a.SetPolicy("/"+serviceName+"/"+method.Name, policy)
+	}
+}
+
+// PublicMethods returns the methods whose policy allows anonymous calls
+func (a *Authorizer) PublicMethods() map[string]bool {
+	methods := make(map[string]bool)
+	for method, policy := range a.policies {
+		if policy.GetPublic() || policy.GetAnonymous() {
+			methods[method] = true
+		}
+	}
+	return methods
+}
+
+// AnonymousMethods returns the methods whose policy ignores the bearer token
+func (a *Authorizer) AnonymousMethods() map[string]bool {
+	methods := make(map[string]bool)
+	for method, policy := range a.policies {
+		if policy.GetAnonymous() {
+			methods[method] = true
+		}
+	}
+	return methods
+}
+
+// LoadPolicies reads the policy of every method of the registered services.
+// It must be called after all services are registered and before the server starts serving,
+// and returns an error listing every method without a policy.
+func (a *Authorizer) LoadPolicies(services map[string]grpc.ServiceInfo) error {
+	var missing []string
+	for serviceName, info := range services {
+		for _, method := range info.Methods {
+			fullMethod := "/" + serviceName + "/" + method.Name
+			if _, ok := a.policies[fullMethod]; ok {
+				continue
+			}
+
+			policy := methodPolicy(serviceName, method.Name)
+			if policy == nil {
+				missing = append(missing, fullMethod)
+				continue
+			}
+			a.policies[fullMethod] = policy
+		}
+	}
+
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("methods without an (oauth.v1.auth) policy: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// methodPolicy reads the (oauth.v1.auth) option from the method descriptor
+func methodPolicy(serviceName, methodName string) *oauth.AuthPolicy {
+	desc, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(serviceName))
+	if err != nil {
+		return nil
+	}
+	service, ok := desc.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil
+	}
+	method := service.Methods().ByName(protoreflect.Name(methodName))
+	if method == nil || method.Options() == nil || !proto.HasExtension(method.Options(), oauth.E_Auth) {
+		return nil
+	}
+	policy, _ := proto.GetExtension(method.Options(), oauth.E_Auth).(*oauth.AuthPolicy)
+	return policy
+}
+
+// Authorize checks the caller in ctx against the policy of fullMethod
+func (a *Authorizer) Authorize(ctx context.Context, fullMethod string) error {
+	policy, ok := a.policies[fullMethod]
+	if !ok {
+		GetLogger(ctx).Errorf("no authorization policy for %s", fullMethod)
+		return StatusWithReason(codes.PermissionDenied, ReasonNoPolicy, "method has no authorization policy")
+	}
+	if policy.GetPublic() || policy.GetAnonymous() {
+		return nil
+	}
+
+	identity, err := GetIdentity(ctx)
+	if err != nil {
+		return err
+	}
+
+	if roles := policy.GetRoles(); len(roles) > 0 {
+		granted := false
+		for _, role := range roles {
+			if identity.HasClientRole(role) {
+				granted = true
+				break
+			}
+		}
+		if !granted {
+			return StatusWithReason(codes.PermissionDenied, ReasonMissingRole, fmt.Sprintf("requires one of roles %v", roles))
+		}
+	}
+	for _, scope := range policy.GetScopes() {
+		if !identity.HasScope(scope) {
+			return StatusWithReason(codes.PermissionDenied, ReasonMissingScope, fmt.Sprintf("requires scope %s", scope))
+		}
+	}
+
+	return nil
+}
+
+// AuthorizationInterceptor enforces method policies, it must run after the identity interceptor
+func AuthorizationInterceptor(authz *Authorizer) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		if err := authz.Authorize(ctx, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// AuthorizationStreamInterceptor is the streaming counterpart of AuthorizationInterceptor
+func AuthorizationStreamInterceptor(authz *Authorizer) grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		stream grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		if err := authz.Authorize(stream.Context(), info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, stream)
+	}
+}
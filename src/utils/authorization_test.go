@@ -0,0 +1,121 @@
+package utils
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/omnsight/omnauth/gen/oauth/v1"
+)
+
+const getUserMethod = "/oauth.v1.AuthService/GetUser"
+
+func authServiceInfo() map[string]grpc.ServiceInfo {
+	server := grpc.NewServer()
+	oauth.RegisterAuthServiceServer(server, oauth.UnimplementedAuthServiceServer{})
+	return server.GetServiceInfo()
+}
+
+func callerContext(claims jwt.MapClaims) context.Context {
+	return WithIdentity(context.Background(), NewIdentity(claims, "omniauth"))
+}
+
+func TestAuthorizerLoadsPoliciesFromDescriptors(t *testing.T) {
+	authz := NewAuthorizer()
+	if err := authz.LoadPolicies(authServiceInfo()); err != nil {
+		t.Fatalf("expected every AuthService method to have a policy: %v", err)
+	}
+
+	user := callerContext(jwt.MapClaims{
+		"sub":             "user-1",
+		"resource_access": map[string]interface{}{"omniauth": map[string]interface{}{"roles": []interface{}{"user"}}},
+	})
+	if err := authz.Authorize(user, getUserMethod); err != nil {
+		t.Errorf("expected user role to be granted: %v", err)
+	}
+
+	// Roles of other clients don't count
+	other := callerContext(jwt.MapClaims{
+		"sub":             "user-2",
+		"resource_access": map[string]interface{}{"omndapi": map[string]interface{}{"roles": []interface{}{"user"}}},
+	})
+	err := authz.Authorize(other, getUserMethod)
+	if status.Code(err) != codes.PermissionDenied || errorReason(t, err) != ReasonMissingRole {
+		t.Errorf("expected missing role, got %v", err)
+	}
+
+	if err := authz.Authorize(context.Background(), getUserMethod); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected anonymous caller to be rejected, got %v", err)
+	}
+}
+
+func TestAuthorizerFailsClosed(t *testing.T) {
+	server := grpc.NewServer()
+	server.RegisterService(&grpc.ServiceDesc{
+		ServiceName: "test.Watcher",
+		HandlerType: (*interface{})(nil),
+		Streams:     []grpc.StreamDesc{watchDesc},
+	}, struct{}{})
+
+	authz := NewAuthorizer()
+	err := authz.LoadPolicies(server.GetServiceInfo())
+	if err == nil || !strings.Contains(err.Error(), "/test.Watcher/Watch") {
+		t.Fatalf("expected unannotated method to be reported, got %v", err)
+	}
+
+	ctx := callerContext(jwt.MapClaims{"sub": "user-1"})
+	err = authz.Authorize(ctx, "/test.Watcher/Watch")
+	if status.Code(err) != codes.PermissionDenied || errorReason(t, err) != ReasonNoPolicy {
+		t.Errorf("expected method without policy to be denied, got %v", err)
+	}
+
+	// An explicit policy covers methods we can't annotate
+	authz = NewAuthorizer()
+	authz.SetPolicy("/test.Watcher/Watch", &oauth.AuthPolicy{Public: true})
+	if err := authz.LoadPolicies(server.GetServiceInfo()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := authz.Authorize(context.Background(), "/test.Watcher/Watch"); err != nil {
+		t.Errorf("expected public method to be allowed: %v", err)
+	}
+}
+
+func TestAuthorizerScopes(t *testing.T) {
+	authz := NewAuthorizer()
+	authz.SetPolicy(getUserMethod, &oauth.AuthPolicy{Scopes: []string{"profile", "email"}})
+
+	if err := authz.Authorize(callerContext(jwt.MapClaims{"sub": "u", "scope": "openid profile email"}), getUserMethod); err != nil {
+		t.Errorf("expected scopes to be granted: %v", err)
+	}
+	err := authz.Authorize(callerContext(jwt.MapClaims{"sub": "u", "scope": "openid profile"}), getUserMethod)
+	if status.Code(err) != codes.PermissionDenied || errorReason(t, err) != ReasonMissingScope {
+		t.Errorf("expected missing scope, got %v", err)
+	}
+}
+
+func TestAuthorizerSetPublicKeepsRoleChecks(t *testing.T) {
+	authz := NewAuthorizer()
+	err := authz.SetPublic("/oauth.v1.AuthService/DeleteUser")
+	if err == nil || !strings.Contains(err.Error(), "DeleteUser") {
+		t.Fatalf("expected a method requiring roles to be refused, got %v", err)
+	}
+	if err := authz.SetPublic("/oauth.v1.AuthService/Login"); err != nil {
+		t.Errorf("expected a method without roles to be made public: %v", err)
+	}
+	if err := authz.SetPublic("/test.Watcher/Watch"); err != nil {
+		t.Errorf("expected an unannotated method to be made public: %v", err)
+	}
+
+	if err := authz.LoadPolicies(authServiceInfo()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = authz.Authorize(callerContext(jwt.MapClaims{"sub": "user-1"}), "/oauth.v1.AuthService/DeleteUser")
+	if status.Code(err) != codes.PermissionDenied || errorReason(t, err) != ReasonMissingRole {
+		t.Errorf("expected DeleteUser to still require a role, got %v", err)
+	}
+}
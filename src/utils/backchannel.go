@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// BackchannelLogoutEvent is the events member marking a logout token
+const BackchannelLogoutEvent = "http://schemas.openid.net/event/backchannel-logout"
+
+// BackchannelLogout receives OpenID Connect back-channel logout tokens from Keycloak and denies
+// the tokens of the logged out session, or of every session of the user when the logout token has no sid.
+type BackchannelLogout struct {
+	// Verifier checks the signature, it must not be TrustedGatewayVerifier since Keycloak calls us directly
+	Verifier TokenVerifier
+	// Issuer is the expected iss claim
+	Issuer string
+	// ClientID must be in the aud claim
+	ClientID string
+	Leeway   time.Duration
+	Denylist *TokenDenylist
+}
+
+// Handler serves POST requests with a logout_token form parameter
+// (OpenID Connect Back-Channel Logout 1.0, section 2.5)
+func (b *BackchannelLogout) Handler(c *gin.Context) {
+	ctx := c.Request.Context()
+	logger := GetLogger(ctx)
+	c.Header("Cache-Control", "no-store")
+
+	claims, err := b.validate(ctx, c.PostForm("logout_token"))
+	if err != nil {
+		logger.WithError(err).Warn("rejected back-channel logout token")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_request", "error_description": err.Error()})
+		return
+	}
+
+	sid, _ := claims["sid"].(string)
+	sub, _ := claims.GetSubject()
+	if sid != "" {
+		b.Denylist.RevokeSession(sid)
+	} else {
+		revokedAt := time.Now()
+		if iat, err := claims.GetIssuedAt(); err == nil && iat != nil {
+			revokedAt = iat.Time
+		}
+		b.Denylist.RevokeSubject(sub, revokedAt)
+	}
+	logger.WithFields(logrus.Fields{"sid": sid, "sub": sub}).Info("received back-channel logout")
+	c.Status(http.StatusOK)
+}
+
+// validate checks a logout token as described in section 2.6 of the specification
+func (b *BackchannelLogout) validate(ctx context.Context, token string) (jwt.MapClaims, error) {
+	if token == "" {
+		return nil, errors.New("missing logout_token")
+	}
+	claims, err := b.Verifier.Verify(ctx, token)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature: %w", err)
+	}
+
+	err = jwt.NewValidator(
+		jwt.WithIssuer(b.Issuer),
+		jwt.WithAudience(b.ClientID),
+		jwt.WithIssuedAt(),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(b.Leeway),
+	).Validate(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	events, _ := claims["events"].(map[string]interface{})
+	if _, ok := events[BackchannelLogoutEvent].(map[string]interface{}); !ok {
+		return nil, errors.New("events claim has no back-channel logout event")
+	}
+	sid, _ := claims["sid"].(string)
+	sub, _ := claims["sub"].(string)
+	if sid == "" && sub == "" {
+		return nil, errors.New("token has neither sid nor sub")
+	}
+	// A nonce would make it an ID token
+	if _, ok := claims["nonce"]; ok {
+		return nil, errors.New("logout tokens must not contain a nonce")
+	}
+	return claims, nil
+}
@@ -0,0 +1,92 @@
+package utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestBackchannelLogout(t *testing.T) {
+	realm := newFakeRealm(t)
+	key := realm.addKey(t, "k1")
+	forgedKey := newFakeRealm(t).addKey(t, "k1")
+	denylist := NewTokenDenylistWithTTL(time.Minute)
+	logout := &BackchannelLogout{
+		Verifier: NewJWKSVerifier(realm.helper()),
+		Issuer:   "https://sso.example.com/realms/omni",
+		ClientID: "omniauth",
+		Denylist: denylist,
+	}
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/auth/backchannel-logout", logout.Handler)
+
+	post := func(token string) int {
+		form := url.Values{"logout_token": {token}}
+		req := httptest.NewRequest(http.MethodPost, "/auth/backchannel-logout", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+	logoutClaims := func(change func(claims jwt.MapClaims)) jwt.MapClaims {
+		now := time.Now()
+		claims := jwt.MapClaims{
+			"iss":    "https://sso.example.com/realms/omni",
+			"aud":    "omniauth",
+			"iat":    now.Unix(),
+			"exp":    now.Add(time.Minute).Unix(),
+			"jti":    "jti-1",
+			"sub":    "user-1",
+			"sid":    "session-1",
+			"events": map[string]interface{}{BackchannelLogoutEvent: map[string]interface{}{}},
+		}
+		change(claims)
+		return claims
+	}
+
+	invalid := map[string]string{
+		"missing token":  "",
+		"forged":         signToken(t, forgedKey, "k1", logoutClaims(func(jwt.MapClaims) {})),
+		"other issuer":   signToken(t, key, "k1", logoutClaims(func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" })),
+		"other audience": signToken(t, key, "k1", logoutClaims(func(c jwt.MapClaims) { c["aud"] = "other" })),
+		"expired":        signToken(t, key, "k1", logoutClaims(func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Minute).Unix() })),
+		"missing event":  signToken(t, key, "k1", logoutClaims(func(c jwt.MapClaims) { c["events"] = map[string]interface{}{} })),
+		"no sid nor sub": signToken(t, key, "k1", logoutClaims(func(c jwt.MapClaims) { delete(c, "sid"); delete(c, "sub") })),
+		"id token":       signToken(t, key, "k1", logoutClaims(func(c jwt.MapClaims) { c["nonce"] = "n" })),
+	}
+	for name, token := range invalid {
+		if code := post(token); code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", name, code)
+		}
+	}
+	if denylist.IsRevoked("session-1", "user-1", time.Now().Add(-time.Hour)) {
+		t.Fatal("rejected logout tokens must not revoke anything")
+	}
+
+	// A session logout only denies that session
+	if code := post(signToken(t, key, "k1", logoutClaims(func(jwt.MapClaims) {}))); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if !denylist.IsRevoked("session-1", "user-1", time.Now()) || denylist.IsRevoked("session-2", "user-1", time.Now().Add(-time.Hour)) {
+		t.Error("expected only session-1 to be revoked")
+	}
+
+	// Without sid every token of the user issued before the logout is denied
+	issuedBefore := time.Now().Add(-time.Minute)
+	if code := post(signToken(t, key, "k1", logoutClaims(func(c jwt.MapClaims) { delete(c, "sid") }))); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if !denylist.IsRevoked("session-2", "user-1", issuedBefore) {
+		t.Error("expected earlier tokens of user-1 to be revoked")
+	}
+	if denylist.IsRevoked("session-3", "user-1", time.Now().Add(time.Minute)) || denylist.IsRevoked("session-2", "user-2", issuedBefore) {
+		t.Error("expected later tokens and other users to be accepted")
+	}
+}
@@ -0,0 +1,382 @@
+package utils
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Nerzal/gocloak/v13"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/singleflight"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Browser session environment variable keys
+const (
+	// AuthCallbackURL is the public URL of /auth/callback, setting it enables the browser session routes
+	AuthCallbackURL = "AUTH_CALLBACK_URL"
+	// AuthSessionKey is the base64 encoded 32 byte key encrypting the session cookies
+	AuthSessionKey = "AUTH_SESSION_KEY"
+	// AuthPostLogoutURL is where Keycloak sends the browser after logout, defaults to the callback URL's origin
+	AuthPostLogoutURL = "AUTH_POST_LOGOUT_URL"
+	// AuthAllowedOrigins is a comma separated list of origins cookie authenticated requests may come from,
+	// defaults to the callback URL's origin
+	AuthAllowedOrigins = "AUTH_ALLOWED_ORIGINS"
+)
+
+// Cookies set by BrowserAuth
+const (
+	SessionCookie = "omniauth_session"
+	loginCookie   = "omniauth_login"
+)
+
+const (
+	// loginTimeout bounds how long a user may take on the Keycloak login page
+	loginTimeout = 10 * time.Minute
+	// accessTokenMargin refreshes access tokens this long before they expire
+	accessTokenMargin = 30 * time.Second
+	// defaultSessionLifetime is used when Keycloak issues refresh tokens without expiry
+	defaultSessionLifetime = 12 * time.Hour
+	// refreshTimeout bounds a shared refresh, which doesn't end when its requests give up
+	refreshTimeout = 10 * time.Second
+)
+
+// loginState is kept in the encrypted login cookie between /auth/login and /auth/callback
+type loginState struct {
+	State        string `json:"state"`
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"code_verifier"`
+	Redirect     string `json:"redirect"`
+}
+
+// BrowserAuth implements the authorization code flow with PKCE for browsers (backend for frontend).
+// Tokens stay in the SessionStore, the browser only holds an encrypted HttpOnly session cookie.
+type BrowserAuth struct {
+	helper *CloakHelper
+	store  SessionStore
+	cipher *CookieCipher
+
+	// Issuer is the browser facing realm URL, the authorization and logout endpoints live below it
+	Issuer        string
+	CallbackURL   string
+	PostLogoutURL string
+	// Secure marks the cookies Secure, it is set when the callback URL uses https
+	Secure bool
+	// AllowedOrigins are the origins cookie authenticated requests may come from
+	AllowedOrigins map[string]bool
+
+	csrfKey []byte
+
+	refreshGroup singleflight.Group
+}
+
+// NewBrowserAuth builds the browser flow from the environment. Without AUTH_SESSION_KEY a random key is used,
+// so sessions don't survive restarts.
+func NewBrowserAuth(helper *CloakHelper, issuer string, store SessionStore) (*BrowserAuth, error) {
+	callback, err := url.Parse(os.Getenv(AuthCallbackURL))
+	if err != nil || callback.Scheme == "" || callback.Host == "" {
+		return nil, fmt.Errorf("invalid %s, expected an absolute URL", AuthCallbackURL)
+	}
+
+	var key []byte
+	if encoded := os.Getenv(AuthSessionKey); encoded != "" {
+		key, err = base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(key) != 32 {
+			return nil, fmt.Errorf("invalid %s, expected 32 base64 encoded bytes", AuthSessionKey)
+		}
+	} else {
+		logrus.Warnf("%s is not set, browser sessions won't survive restarts", AuthSessionKey)
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, fmt.Errorf("failed to generate session key: %w", err)
+		}
+	}
+	cookieCipher, err := NewCookieCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	allowedOrigins := map[string]bool{callback.Scheme + "://" + callback.Host: true}
+	if origins, ok := os.LookupEnv(AuthAllowedOrigins); ok {
+		allowedOrigins = make(map[string]bool)
+		for _, origin := range splitList(origins) {
+			allowedOrigins[strings.TrimRight(origin, "/")] = true
+		}
+	}
+
+	postLogoutURL := os.Getenv(AuthPostLogoutURL)
+	if postLogoutURL == "" {
+		postLogoutURL = callback.Scheme + "://" + callback.Host + "/"
+	}
+
+	return &BrowserAuth{
+		helper:         helper,
+		store:          store,
+		cipher:         cookieCipher,
+		Issuer:         strings.TrimRight(issuer, "/"),
+		CallbackURL:    callback.String(),
+		PostLogoutURL:  postLogoutURL,
+		Secure:         callback.Scheme == "https",
+		AllowedOrigins: allowedOrigins,
+		csrfKey:        deriveKey(key, "csrf"),
+	}, nil
+}
+
+// Register adds /auth/login, /auth/callback and /auth/logout
+func (b *BrowserAuth) Register(r gin.IRouter) {
+	r.GET("/auth/login", b.Login)
+	r.GET("/auth/callback", b.Callback)
+	r.POST("/auth/logout", b.Logout)
+}
+
+// Login redirects to Keycloak. The optional redirect query parameter is the local path to return to afterwards.
+func (b *BrowserAuth) Login(c *gin.Context) {
+	redirect := c.Query("redirect")
+	if !isLocalPath(redirect) {
+		redirect = "/"
+	}
+	state := loginState{
+		State:        RandomToken(32),
+		Nonce:        RandomToken(32),
+		CodeVerifier: RandomToken(32),
+		Redirect:     redirect,
+	}
+	value, _ := json.Marshal(state)
+	b.setCookie(c, loginCookie, b.cipher.Seal(loginCookie, value), "/auth/callback", int(loginTimeout.Seconds()))
+
+	challenge := sha256.Sum256([]byte(state.CodeVerifier))
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {b.helper.ClientID},
+		"redirect_uri":          {b.CallbackURL},
+		"scope":                 {"openid"},
+		"state":                 {state.State},
+		"nonce":                 {state.Nonce},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}
+	c.Redirect(http.StatusFound, b.Issuer+"/protocol/openid-connect/auth?"+query.Encode())
+}
+
+// Callback redeems the authorization code, stores the tokens and sets the session cookie
+func (b *BrowserAuth) Callback(c *gin.Context) {
+	ctx := c.Request.Context()
+	logger := GetLogger(ctx)
+
+	sealed, err := c.Cookie(loginCookie)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "login expired, start again at /auth/login"})
+		return
+	}
+	b.setCookie(c, loginCookie, "", "/auth/callback", -1)
+
+	var state loginState
+	value, err := b.cipher.Open(loginCookie, sealed)
+	if err == nil {
+		err = json.Unmarshal(value, &state)
+	}
+	if err != nil || subtle.ConstantTimeCompare([]byte(state.State), []byte(c.Query("state"))) != 1 {
+		logger.WithError(err).Warn("rejected login callback with invalid state")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid login state"})
+		return
+	}
+	if providerError := c.Query("error"); providerError != "" {
+		logger.WithFields(logrus.Fields{
+			"error":       providerError,
+			"description": c.Query("error_description"),
+		}).Info("login was not completed")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": providerError})
+		return
+	}
+
+	token, err := b.helper.ExchangeCode(ctx, c.Query("code"), b.CallbackURL, state.CodeVerifier)
+	if err != nil {
+		b.abortWithError(c, TokenError(ctx, err))
+		return
+	}
+	// The ID token comes straight from the token endpoint over our client connection,
+	// so only the nonce is checked (OpenID Connect Core 3.1.3.7)
+	var claims jwt.MapClaims
+	if _, _, err := jwt.NewParser().ParseUnverified(token.IDToken, &claims); err != nil || claims["nonce"] != state.Nonce {
+		logger.WithError(err).Warn("rejected ID token with invalid nonce")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID token"})
+		return
+	}
+	subject, _ := claims.GetSubject()
+
+	session := newSession(RandomToken(32), token)
+	session.UserID = subject
+	if err := b.store.Save(ctx, session); err != nil {
+		logger.WithError(err).Error("failed to save session")
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "failed to save session"})
+		return
+	}
+	b.setCookie(c, SessionCookie, b.cipher.Seal(SessionCookie, []byte(session.ID)), "/", 0)
+	b.setCSRFCookie(c, b.csrfToken(session.ID), 0)
+	c.Redirect(http.StatusFound, state.Redirect)
+}
+
+// Logout ends the session locally and at Keycloak, then redirects to Keycloak's logout endpoint
+func (b *BrowserAuth) Logout(c *gin.Context) {
+	ctx := c.Request.Context()
+	session := b.session(c)
+	b.clearSessionCookies(c)
+	if session == nil {
+		c.Redirect(http.StatusSeeOther, b.PostLogoutURL)
+		return
+	}
+
+	if err := b.store.Delete(ctx, session.ID); err != nil {
+		GetLogger(ctx).WithError(err).Error("failed to delete session")
+	}
+	if err := b.helper.RevokeRefreshToken(ctx, session.RefreshToken); err != nil {
+		GetLogger(ctx).WithError(err).Warn("failed to revoke refresh token of ended session")
+	}
+
+	query := url.Values{
+		"client_id":                {b.helper.ClientID},
+		"post_logout_redirect_uri": {b.PostLogoutURL},
+		"id_token_hint":            {session.IDToken},
+	}
+	c.Redirect(http.StatusSeeOther, b.Issuer+"/protocol/openid-connect/logout?"+query.Encode())
+}
+
+// SessionMiddleware turns the session cookie into an Authorization header, which the gateway
+// forwards as authorization metadata. Requests carrying their own Authorization header are left alone.
+func (b *BrowserAuth) SessionMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			if session := b.session(c); session != nil {
+				c.Request.Header.Set("Authorization", "Bearer "+session.AccessToken)
+			}
+		}
+		c.Next()
+	}
+}
+
+// session returns the session of the request's cookie with a fresh access token, or nil
+func (b *BrowserAuth) session(c *gin.Context) *Session {
+	sealed, err := c.Cookie(SessionCookie)
+	if err != nil {
+		return nil
+	}
+	ctx := c.Request.Context()
+	id, err := b.cipher.Open(SessionCookie, sealed)
+	if err != nil {
+		b.clearSessionCookies(c)
+		return nil
+	}
+
+	session, err := b.store.Get(ctx, string(id))
+	if errors.Is(err, ErrSessionNotFound) {
+		b.clearSessionCookies(c)
+		return nil
+	}
+	if err != nil {
+		GetLogger(ctx).WithError(err).Error("failed to load session")
+		return nil
+	}
+	if time.Until(session.AccessExpiresAt) > accessTokenMargin {
+		return session
+	}
+
+	// Concurrent requests of one session share a refresh, Keycloak may rotate the refresh token
+	refreshed, err, _ := b.refreshGroup.Do(session.ID, func() (interface{}, error) {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), refreshTimeout)
+		defer cancel()
+		return b.refresh(ctx, session)
+	})
+	if err != nil {
+		if status.Code(err) == codes.Unauthenticated {
+			b.clearSessionCookies(c)
+			return nil
+		}
+		GetLogger(ctx).WithError(err).Warn("failed to refresh session, using the current access token")
+		return session
+	}
+	return refreshed.(*Session)
+}
+
+func (b *BrowserAuth) refresh(ctx context.Context, session *Session) (*Session, error) {
+	token, err := b.helper.RefreshUserToken(ctx, session.RefreshToken)
+	if err != nil {
+		err = TokenError(ctx, err)
+		if status.Code(err) == codes.Unauthenticated {
+			// The Keycloak session ended
+			if err := b.store.Delete(ctx, session.ID); err != nil {
+				GetLogger(ctx).WithError(err).Error("failed to delete session")
+			}
+		}
+		return nil, err
+	}
+
+	refreshed := newSession(session.ID, token)
+	refreshed.UserID = session.UserID
+	if refreshed.IDToken == "" {
+		refreshed.IDToken = session.IDToken
+	}
+	if err := b.store.Save(ctx, refreshed); err != nil {
+		return nil, fmt.Errorf("failed to save session: %w", err)
+	}
+	return refreshed, nil
+}
+
+func newSession(id string, token *gocloak.JWT) *Session {
+	now := time.Now()
+	lifetime := time.Duration(token.RefreshExpiresIn) * time.Second
+	if lifetime <= 0 {
+		lifetime = defaultSessionLifetime
+	}
+	return &Session{
+		ID:              id,
+		AccessToken:     token.AccessToken,
+		RefreshToken:    token.RefreshToken,
+		IDToken:         token.IDToken,
+		AccessExpiresAt: now.Add(time.Duration(token.ExpiresIn) * time.Second),
+		ExpiresAt:       now.Add(lifetime),
+	}
+}
+
+// clearSessionCookies removes the session and CSRF cookies
+func (b *BrowserAuth) clearSessionCookies(c *gin.Context) {
+	b.setCookie(c, SessionCookie, "", "/", -1)
+	b.setCSRFCookie(c, "", -1)
+}
+
+// setCookie sets an HttpOnly, SameSite=Lax cookie. Lax still sends it on the top level redirect back from Keycloak.
+func (b *BrowserAuth) setCookie(c *gin.Context, name, value, path string, maxAge int) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(name, value, maxAge, path, "", b.Secure, true)
+}
+
+func (b *BrowserAuth) abortWithError(c *gin.Context, err error) {
+	st := status.Convert(err)
+	httpStatus := http.StatusBadGateway
+	switch st.Code() {
+	case codes.Unauthenticated:
+		httpStatus = http.StatusUnauthorized
+	case codes.PermissionDenied:
+		httpStatus = http.StatusForbidden
+	case codes.InvalidArgument, codes.FailedPrecondition:
+		httpStatus = http.StatusBadRequest
+	}
+	c.JSON(httpStatus, gin.H{"error": st.Message()})
+}
+
+// isLocalPath reports whether redirect is a path on this host, so the login can't be used as an open redirect
+func isLocalPath(redirect string) bool {
+	return strings.HasPrefix(redirect, "/") && !strings.HasPrefix(redirect, "//") && !strings.ContainsAny(redirect, "\\\r\n")
+}
@@ -0,0 +1,269 @@
+package utils
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Nerzal/gocloak/v13"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// fakeTokenEndpoint issues tokens for authorization codes whose PKCE verifier matches the challenge
+type fakeTokenEndpoint struct {
+	server *httptest.Server
+
+	mu        sync.Mutex
+	challenge string
+	nonce     string
+	expiresIn int
+	refreshes int
+	revoked   []string
+}
+
+func newFakeTokenEndpoint(t *testing.T) *fakeTokenEndpoint {
+	t.Helper()
+	f := &fakeTokenEndpoint{expiresIn: 300}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /realms/"+testRealm+"/protocol/openid-connect/token", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		r.ParseForm()
+		switch r.Form.Get("grant_type") {
+		case "authorization_code":
+			verifier := sha256.Sum256([]byte(r.Form.Get("code_verifier")))
+			if r.Form.Get("code") != "code-1" || base64.RawURLEncoding.EncodeToString(verifier[:]) != f.challenge {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant", "error_description": "Code not valid"})
+				return
+			}
+			idToken, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "u1", "nonce": f.nonce}).SignedString([]byte("test"))
+			writeJSON(w, http.StatusOK, gocloak.JWT{AccessToken: "access-0", RefreshToken: "refresh-0", IDToken: idToken, ExpiresIn: f.expiresIn, RefreshExpiresIn: 1800})
+		case "refresh_token":
+			if r.Form.Get("refresh_token") == "revoked" {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant", "error_description": "Session not active"})
+				return
+			}
+			f.refreshes++
+			writeJSON(w, http.StatusOK, gocloak.JWT{AccessToken: "access-refreshed", RefreshToken: "refresh-refreshed", ExpiresIn: 300, RefreshExpiresIn: 1800})
+		}
+	})
+	mux.HandleFunc("POST /realms/"+testRealm+"/protocol/openid-connect/revoke", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		r.ParseForm()
+		f.revoked = append(f.revoked, r.Form.Get("token"))
+	})
+	f.server = httptest.NewServer(mux)
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func newTestBrowserAuth(t *testing.T, f *fakeTokenEndpoint, store SessionStore) (*BrowserAuth, *gin.Engine) {
+	t.Helper()
+	t.Setenv(AuthCallbackURL, "https://app.example.com/auth/callback")
+	helper := &CloakHelper{Client: gocloak.NewClient(f.server.URL), URL: f.server.URL, Realm: testRealm, ClientID: "omniauth", ClientSecret: "secret"}
+	auth, err := NewBrowserAuth(helper, "https://sso.example.com/realms/"+testRealm, store)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	auth.Register(r)
+	r.GET("/v1/me", auth.SessionMiddleware(), func(c *gin.Context) {
+		c.String(http.StatusOK, c.GetHeader("Authorization"))
+	})
+	return auth, r
+}
+
+func serve(r http.Handler, method, target string, cookies []*http.Cookie, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, nil)
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+	for key, values := range header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func cookieNamed(w *httptest.ResponseRecorder, name string) *http.Cookie {
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.Name == name {
+			return cookie
+		}
+	}
+	return nil
+}
+
+// login runs /auth/login and /auth/callback and returns the session cookie
+func login(t *testing.T, f *fakeTokenEndpoint, r http.Handler) *http.Cookie {
+	t.Helper()
+	w := serve(r, http.MethodGet, "/auth/login?redirect=/app", nil, nil)
+	if w.Code != http.StatusFound {
+		t.Fatalf("expected a redirect to Keycloak, got %d", w.Code)
+	}
+	location, _ := url.Parse(w.Header().Get("Location"))
+	query := location.Query()
+	if !strings.HasPrefix(location.String(), "https://sso.example.com/realms/"+testRealm+"/protocol/openid-connect/auth?") ||
+		query.Get("code_challenge_method") != "S256" || query.Get("redirect_uri") != "https://app.example.com/auth/callback" {
+		t.Fatalf("unexpected authorization URL %s", location)
+	}
+	f.mu.Lock()
+	f.challenge, f.nonce = query.Get("code_challenge"), query.Get("nonce")
+	f.mu.Unlock()
+
+	loginState := cookieNamed(w, loginCookie)
+	if loginState == nil || !loginState.HttpOnly || !loginState.Secure {
+		t.Fatalf("expected a secure HttpOnly login cookie, got %v", loginState)
+	}
+	w = serve(r, http.MethodGet, "/auth/callback?code=code-1&state="+query.Get("state"), []*http.Cookie{loginState}, nil)
+	if w.Code != http.StatusFound || w.Header().Get("Location") != "/app" {
+		t.Fatalf("expected a redirect to /app, got %d %s", w.Code, w.Body)
+	}
+	session := cookieNamed(w, SessionCookie)
+	if session == nil || !session.HttpOnly || session.SameSite != http.SameSiteLaxMode {
+		t.Fatalf("expected an HttpOnly SameSite session cookie, got %v", session)
+	}
+	if csrf := cookieNamed(w, CSRFCookie); csrf == nil || csrf.HttpOnly || csrf.Value == "" {
+		t.Fatalf("expected a CSRF cookie readable by scripts, got %v", csrf)
+	}
+	return session
+}
+
+func TestBrowserAuthLogin(t *testing.T) {
+	f := newFakeTokenEndpoint(t)
+	store := NewMemorySessionStore()
+	_, r := newTestBrowserAuth(t, f, store)
+
+	session := login(t, f, r)
+	if strings.Contains(session.Value, "access-0") {
+		t.Error("session cookie must not contain the token")
+	}
+
+	w := serve(r, http.MethodGet, "/v1/me", []*http.Cookie{session}, nil)
+	if w.Body.String() != "Bearer access-0" {
+		t.Errorf("expected the session's access token, got %q", w.Body)
+	}
+	// An explicit Authorization header wins
+	w = serve(r, http.MethodGet, "/v1/me", []*http.Cookie{session}, http.Header{"Authorization": {"Bearer other"}})
+	if w.Body.String() != "Bearer other" {
+		t.Errorf("expected the request's own token, got %q", w.Body)
+	}
+	// Tampered cookies are ignored
+	tampered := *session
+	tampered.Value = session.Value[:len(session.Value)-2] + "AA"
+	w = serve(r, http.MethodGet, "/v1/me", []*http.Cookie{&tampered}, nil)
+	if w.Body.String() != "" {
+		t.Errorf("expected no token for a tampered cookie, got %q", w.Body)
+	}
+}
+
+func TestBrowserAuthCallbackRejectsInvalidState(t *testing.T) {
+	f := newFakeTokenEndpoint(t)
+	_, r := newTestBrowserAuth(t, f, NewMemorySessionStore())
+
+	w := serve(r, http.MethodGet, "/auth/login", nil, nil)
+	w = serve(r, http.MethodGet, "/auth/callback?code=code-1&state=forged", []*http.Cookie{cookieNamed(w, loginCookie)}, nil)
+	if w.Code != http.StatusBadRequest || cookieNamed(w, SessionCookie) != nil {
+		t.Errorf("expected the callback to be rejected, got %d", w.Code)
+	}
+
+	w = serve(r, http.MethodGet, "/auth/callback?code=code-1&state=forged", nil, nil)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected a callback without login cookie to be rejected, got %d", w.Code)
+	}
+}
+
+func TestBrowserAuthRefreshesExpiringTokens(t *testing.T) {
+	f := newFakeTokenEndpoint(t)
+	f.expiresIn = 10
+	store := NewMemorySessionStore()
+	_, r := newTestBrowserAuth(t, f, store)
+	session := login(t, f, r)
+
+	for i := 0; i < 2; i++ {
+		w := serve(r, http.MethodGet, "/v1/me", []*http.Cookie{session}, nil)
+		if w.Body.String() != "Bearer access-refreshed" {
+			t.Errorf("expected the refreshed access token, got %q", w.Body)
+		}
+	}
+	if f.refreshes != 1 {
+		t.Errorf("expected 1 refresh, got %d", f.refreshes)
+	}
+
+	// A session ended at Keycloak is dropped
+	stored := onlySession(t, store)
+	stored.AccessExpiresAt = time.Now()
+	stored.RefreshToken = "revoked"
+	store.Save(context.Background(), stored)
+	w := serve(r, http.MethodGet, "/v1/me", []*http.Cookie{session}, nil)
+	if w.Body.String() != "" || cookieNamed(w, SessionCookie) == nil || cookieNamed(w, SessionCookie).MaxAge >= 0 {
+		t.Errorf("expected the session cookie to be cleared, got %q", w.Body)
+	}
+	if store.Len() != 0 {
+		t.Errorf("expected the session to be deleted, %d left", store.Len())
+	}
+}
+
+func TestBrowserAuthLogout(t *testing.T) {
+	f := newFakeTokenEndpoint(t)
+	store := NewMemorySessionStore()
+	_, r := newTestBrowserAuth(t, f, store)
+	session := login(t, f, r)
+
+	w := serve(r, http.MethodPost, "/auth/logout", []*http.Cookie{session}, nil)
+	location, _ := url.Parse(w.Header().Get("Location"))
+	if w.Code != http.StatusSeeOther || location.Path != "/realms/"+testRealm+"/protocol/openid-connect/logout" ||
+		location.Query().Get("post_logout_redirect_uri") != "https://app.example.com/" || location.Query().Get("id_token_hint") == "" {
+		t.Errorf("expected a redirect to Keycloak's logout, got %d %s", w.Code, location)
+	}
+	if store.Len() != 0 || len(f.revoked) != 1 || f.revoked[0] != "refresh-0" {
+		t.Errorf("expected the session to be deleted and its refresh token revoked, got %d sessions, revoked %v", store.Len(), f.revoked)
+	}
+
+	w = serve(r, http.MethodGet, "/v1/me", []*http.Cookie{session}, nil)
+	if w.Body.String() != "" {
+		t.Errorf("expected no token after logout, got %q", w.Body)
+	}
+}
+
+func TestLocalRedirects(t *testing.T) {
+	for redirect, want := range map[string]bool{
+		"/":                        true,
+		"/app?tab=1":               true,
+		"":                         false,
+		"//evil.example.com":       false,
+		"/\\evil.example.com":      false,
+		"https://evil.example.com": false,
+	} {
+		if isLocalPath(redirect) != want {
+			t.Errorf("isLocalPath(%q) = %v, want %v", redirect, !want, want)
+		}
+	}
+}
+
+func onlySession(t *testing.T, store *MemorySessionStore) *Session {
+	t.Helper()
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if len(store.sessions) != 1 {
+		t.Fatalf("expected 1 session, got %d", len(store.sessions))
+	}
+	for _, session := range store.sessions {
+		return &session
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Claim validation environment variable keys
+const (
+	AuthIssuer            = "AUTH_ISSUER"
+	AuthAudiences         = "AUTH_AUDIENCES"
+	AuthAuthorizedParties = "AUTH_AUTHORIZED_PARTIES"
+	AuthClockSkew         = "AUTH_CLOCK_SKEW"
+)
+
+const defaultClockSkew = 30 * time.Second
+
+// ClaimsValidator checks the standard claims of a verified token
+type ClaimsValidator struct {
+	// Issuer is the expected iss claim
+	Issuer string
+	// Audiences lists accepted aud values, a token must carry at least one. Empty skips the check.
+	Audiences []string
+	// AuthorizedParties lists accepted azp values (the client the token was issued to). Empty skips the check.
+	AuthorizedParties []string
+	// Leeway is the tolerated clock skew for exp and nbf
+	Leeway time.Duration
+}
+
+// NewClaimsValidator builds the validator from the environment.
+// The issuer defaults to the realm URL and azp to our own client. Keycloak leaves the
+// requesting client out of aud, so the audience check is only enabled when configured.
+func NewClaimsValidator(helper *CloakHelper) (*ClaimsValidator, error) {
+	validator := &ClaimsValidator{
+		Issuer:            helper.RealmURL(),
+		AuthorizedParties: []string{helper.ClientID},
+		Leeway:            defaultClockSkew,
+	}
+
+	if issuer := os.Getenv(AuthIssuer); issuer != "" {
+		validator.Issuer = issuer
+	}
+	if audiences, ok := os.LookupEnv(AuthAudiences); ok {
+		validator.Audiences = splitList(audiences)
+	}
+	if parties, ok := os.LookupEnv(AuthAuthorizedParties); ok {
+		validator.AuthorizedParties = splitList(parties)
+	}
+	if skew := os.Getenv(AuthClockSkew); skew != "" {
+		leeway, err := time.ParseDuration(skew)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", AuthClockSkew, err)
+		}
+		validator.Leeway = leeway
+	}
+
+	return validator, nil
+}
+
+// Validate returns an Unauthenticated status error describing the first failed claim
+func (v *ClaimsValidator) Validate(claims jwt.MapClaims) error {
+	opts := []jwt.ParserOption{
+		jwt.WithLeeway(v.Leeway),
+		jwt.WithExpirationRequired(),
+	}
+	if v.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(v.Issuer))
+	}
+	if len(v.Audiences) > 0 {
+		opts = append(opts, jwt.WithAudience(v.Audiences...))
+	}
+
+	if err := jwt.NewValidator(opts...).Validate(claims); err != nil {
+		switch {
+		case errors.Is(err, jwt.ErrTokenExpired):
+			return authError(ReasonTokenExpired, "token expired")
+		case errors.Is(err, jwt.ErrTokenNotValidYet), errors.Is(err, jwt.ErrTokenUsedBeforeIssued):
+			return authError(ReasonTokenNotYetValid, "token not valid yet")
+		case errors.Is(err, jwt.ErrTokenInvalidIssuer):
+			return authError(ReasonInvalidIssuer, "token issuer not accepted")
+		case errors.Is(err, jwt.ErrTokenInvalidAudience):
+			return authError(ReasonInvalidAudience, "token audience not accepted")
+		default:
+			return authError(ReasonInvalidTokenClaims, "invalid token claims")
+		}
+	}
+
+	if len(v.AuthorizedParties) > 0 {
+		azp, _ := claims["azp"].(string)
+		if !slices.Contains(v.AuthorizedParties, azp) {
+			return authError(ReasonUnauthorizedParty, "token was not issued to an accepted client")
+		}
+	}
+
+	return nil
+}
+
+// splitList splits a comma separated environment value, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,89 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errorReason extracts the google.rpc.ErrorInfo reason from a status error
+func errorReason(t *testing.T, err error) string {
+	t.Helper()
+	st, ok := status.FromError(err)
+	if !ok {
+		t.Fatalf("expected a status error, got %v", err)
+	}
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			return info.Reason
+		}
+	}
+	return ""
+}
+
+func TestClaimsValidator(t *testing.T) {
+	validator := &ClaimsValidator{
+		Issuer:            "http://keycloak/realms/omni",
+		Audiences:         []string{"omniauth"},
+		AuthorizedParties: []string{"omniauth", "web"},
+		Leeway:            30 * time.Second,
+	}
+	now := time.Now()
+	valid := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss": "http://keycloak/realms/omni",
+			"aud": []interface{}{"omniauth", "account"},
+			"azp": "web",
+			"exp": float64(now.Add(time.Minute).Unix()),
+			"nbf": float64(now.Add(-time.Minute).Unix()),
+		}
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(jwt.MapClaims)
+		reason string
+	}{
+		{"valid", func(c jwt.MapClaims) {}, ""},
+		{"expired within leeway", func(c jwt.MapClaims) { c["exp"] = float64(now.Add(-10 * time.Second).Unix()) }, ""},
+		{"expired", func(c jwt.MapClaims) { c["exp"] = float64(now.Add(-time.Minute).Unix()) }, ReasonTokenExpired},
+		{"missing exp", func(c jwt.MapClaims) { delete(c, "exp") }, ReasonInvalidTokenClaims},
+		{"not yet valid", func(c jwt.MapClaims) { c["nbf"] = float64(now.Add(time.Minute).Unix()) }, ReasonTokenNotYetValid},
+		{"wrong issuer", func(c jwt.MapClaims) { c["iss"] = "http://evil/realms/omni" }, ReasonInvalidIssuer},
+		{"wrong audience", func(c jwt.MapClaims) { c["aud"] = "omndapi" }, ReasonInvalidAudience},
+		{"other client", func(c jwt.MapClaims) { c["azp"] = "omndapi" }, ReasonUnauthorizedParty},
+		{"missing azp", func(c jwt.MapClaims) { delete(c, "azp") }, ReasonUnauthorizedParty},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := valid()
+			tt.mutate(claims)
+			err := validator.Validate(claims)
+			if tt.reason == "" {
+				if err != nil {
+					t.Fatalf("expected claims to be valid: %v", err)
+				}
+				return
+			}
+			if status.Code(err) != codes.Unauthenticated {
+				t.Fatalf("expected Unauthenticated, got %v", err)
+			}
+			if reason := errorReason(t, err); reason != tt.reason {
+				t.Errorf("expected reason %s, got %s", tt.reason, reason)
+			}
+		})
+	}
+}
+
+func TestClaimsValidatorSkipsUnconfiguredChecks(t *testing.T) {
+	validator := &ClaimsValidator{}
+	claims := jwt.MapClaims{"exp": float64(time.Now().Add(time.Minute).Unix())}
+	if err := validator.Validate(claims); err != nil {
+		t.Fatalf("expected claims to be valid: %v", err)
+	}
+}
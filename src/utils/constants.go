@@ -3,4 +3,30 @@ package utils
 const (
 	GrpcPort   = "GRPC_PORT"
 	ServerPort = "SERVER_PORT"
+
+	// AuthTrustedGateway disables token signature verification when set to "true".
+	// Only enable it when the gRPC port is reachable exclusively through a verifying gateway.
+	AuthTrustedGateway = "AUTH_TRUSTED_GATEWAY"
+
+	// AuthPublicMethods is a comma separated list of full method names callable without a token
+	AuthPublicMethods = "AUTH_PUBLIC_METHODS"
+
+	// GrpcReflection registers the gRPC reflection service when set to "true"
+	GrpcReflection = "GRPC_REFLECTION"
+
+	// JobsEnabled disables the background jobs when set to "false"
+	JobsEnabled = "JOBS_ENABLED"
+
+	// JobsMetrics serves the unauthenticated GET /jobs metrics when set to "true".
+	// Only enable it when the HTTP port isn't reachable from outside the deployment.
+	JobsMetrics = "JOBS_METRICS"
+
+	// TierExpiryInterval is how often expired tiers are demoted, e.g. "5m"
+	TierExpiryInterval = "TIER_EXPIRY_INTERVAL"
+
+	// UnverifiedUserMaxAge enables deleting users whose email is still unverified after this long, e.g. "720h"
+	UnverifiedUserMaxAge = "UNVERIFIED_USER_MAX_AGE"
+
+	// UnverifiedCleanupInterval is how often unverified users are cleaned up, e.g. "1h"
+	UnverifiedCleanupInterval = "UNVERIFIED_CLEANUP_INTERVAL"
 )
@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCSRFMiddleware(t *testing.T) {
+	f := newFakeTokenEndpoint(t)
+	auth, _ := newTestBrowserAuth(t, f, NewMemorySessionStore())
+
+	r := gin.New()
+	r.Use(RequestIDMiddleware(), auth.CSRFMiddleware())
+	auth.Register(r)
+	r.Any("/v1/users", auth.SessionMiddleware(), func(c *gin.Context) {
+		c.String(http.StatusOK, c.GetHeader("Authorization"))
+	})
+
+	session := login(t, f, r)
+	token := auth.csrfToken(mustOpen(t, auth, session.Value))
+	if token == auth.csrfToken("other-session") {
+		t.Fatal("expected CSRF tokens to be bound to the session")
+	}
+
+	tests := []struct {
+		name    string
+		method  string
+		cookies []*http.Cookie
+		header  http.Header
+		want    int
+	}{
+		{"safe method", http.MethodGet, []*http.Cookie{session}, nil, http.StatusOK},
+		{"valid", http.MethodPost, []*http.Cookie{session}, http.Header{"Origin": {"https://app.example.com"}, CSRFHeader: {token}}, http.StatusOK},
+		{"referer fallback", http.MethodPost, []*http.Cookie{session}, http.Header{"Referer": {"https://app.example.com/page"}, CSRFHeader: {token}}, http.StatusOK},
+		{"missing token", http.MethodPost, []*http.Cookie{session}, http.Header{"Origin": {"https://app.example.com"}}, http.StatusForbidden},
+		{"wrong token", http.MethodDelete, []*http.Cookie{session}, http.Header{"Origin": {"https://app.example.com"}, CSRFHeader: {"forged"}}, http.StatusForbidden},
+		{"foreign origin", http.MethodPost, []*http.Cookie{session}, http.Header{"Origin": {"https://evil.example.com"}, CSRFHeader: {token}}, http.StatusForbidden},
+		{"missing origin", http.MethodPost, []*http.Cookie{session}, http.Header{CSRFHeader: {token}}, http.StatusForbidden},
+		{"bearer token", http.MethodPost, []*http.Cookie{session}, http.Header{"Authorization": {"Bearer token"}}, http.StatusOK},
+		{"no cookie", http.MethodPost, nil, nil, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(r, tt.method, "/v1/users", tt.cookies, tt.header)
+			if w.Code != tt.want {
+				t.Errorf("expected %d, got %d %s", tt.want, w.Code, w.Body)
+			}
+			if w.Header().Get("X-Request-Id") == "" {
+				t.Error("expected a request ID")
+			}
+		})
+	}
+
+	// Logout is protected as well
+	w := serve(r, http.MethodPost, "/auth/logout", []*http.Cookie{session}, http.Header{"Origin": {"https://evil.example.com"}})
+	if w.Code != http.StatusForbidden {
+		t.Errorf("expected a cross-site logout to be rejected, got %d", w.Code)
+	}
+}
+
+func mustOpen(t *testing.T, auth *BrowserAuth, sealed string) string {
+	t.Helper()
+	id, err := auth.cipher.Open(SessionCookie, sealed)
+	if err != nil {
+		t.Fatalf("failed to open session cookie: %v", err)
+	}
+	return string(id)
+}
@@ -0,0 +1,129 @@
+package utils
+
+import (
+	"sync"
+	"time"
+)
+
+// AuthDenylistTTL is how long revoked sessions and subjects are remembered, it must cover the access token lifespan
+const AuthDenylistTTL = "AUTH_DENYLIST_TTL"
+
+const defaultDenylistTTL = time.Hour
+
+// ReasonTokenRevoked is returned for tokens of revoked sessions and subjects
+const ReasonTokenRevoked = "TOKEN_REVOKED"
+
+// TokenDenylist remembers revoked Keycloak sessions and subjects, so access tokens issued to them are
+// rejected right away instead of staying valid until they expire. Entries are dropped after the TTL,
+// by then every token issued before the revocation has expired.
+type TokenDenylist struct {
+	ttl time.Duration
+
+	mu       sync.Mutex
+	sessions map[string]time.Time
+	// subjects maps a user ID to its revocation time and the entry's expiry
+	subjects  map[string]subjectRevocation
+	lastSweep time.Time
+}
+
+type subjectRevocation struct {
+	revokedAt time.Time
+	expiresAt time.Time
+}
+
+func NewTokenDenylist() (*TokenDenylist, error) {
+	ttl, err := DurationEnv(AuthDenylistTTL, defaultDenylistTTL)
+	if err != nil {
+		return nil, err
+	}
+	return NewTokenDenylistWithTTL(ttl), nil
+}
+
+func NewTokenDenylistWithTTL(ttl time.Duration) *TokenDenylist {
+	return &TokenDenylist{
+		ttl:       ttl,
+		sessions:  make(map[string]time.Time),
+		subjects:  make(map[string]subjectRevocation),
+		lastSweep: time.Now(),
+	}
+}
+
+// RevokeSession denies the tokens of a session (the sid claim)
+func (d *TokenDenylist) RevokeSession(sessionID string) {
+	if sessionID == "" {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	d.sweep(now)
+	d.sessions[sessionID] = now.Add(d.ttl)
+}
+
+// IsSessionRevoked reports whether tokens of the session are denied
+func (d *TokenDenylist) IsSessionRevoked(sessionID string) bool {
+	if sessionID == "" {
+		return false
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	expiresAt, ok := d.sessions[sessionID]
+	return ok && time.Now().Before(expiresAt)
+}
+
+// RevokeSubject denies the tokens of a user (the sub claim) issued up to revokedAt.
+// Tokens issued later, e.g. after the user signs in again, are accepted.
+func (d *TokenDenylist) RevokeSubject(subject string, revokedAt time.Time) {
+	if subject == "" {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	d.sweep(now)
+	if current, ok := d.subjects[subject]; ok && current.revokedAt.After(revokedAt) {
+		revokedAt = current.revokedAt
+	}
+	d.subjects[subject] = subjectRevocation{revokedAt: revokedAt, expiresAt: now.Add(d.ttl)}
+}
+
+// IsRevoked reports whether a token of the session and subject issued at issuedAt is denied.
+// Tokens without iat are denied whenever their subject is revoked.
+func (d *TokenDenylist) IsRevoked(sessionID, subject string, issuedAt time.Time) bool {
+	if d.IsSessionRevoked(sessionID) {
+		return true
+	}
+	if subject == "" {
+		return false
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	revocation, ok := d.subjects[subject]
+	if !ok || !time.Now().Before(revocation.expiresAt) {
+		return false
+	}
+	// iat has second precision, a token issued within the second of the revocation is denied
+	return issuedAt.IsZero() || !issuedAt.After(revocation.revokedAt)
+}
+
+// sweep drops expired entries at most once per TTL, d.mu must be held
+func (d *TokenDenylist) sweep(now time.Time) {
+	if now.Sub(d.lastSweep) < d.ttl {
+		return
+	}
+	for id, expiresAt := range d.sessions {
+		if !now.Before(expiresAt) {
+			delete(d.sessions, id)
+		}
+	}
+	for subject, revocation := range d.subjects {
+		if !now.Before(revocation.expiresAt) {
+			delete(d.subjects, subject)
+		}
+	}
+	d.lastSweep = now
+}
@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestTokenDenylistExpires(t *testing.T) {
+	denylist := NewTokenDenylistWithTTL(10 * time.Millisecond)
+	denylist.RevokeSession("session-1")
+	denylist.RevokeSession("")
+
+	if !denylist.IsSessionRevoked("session-1") || denylist.IsSessionRevoked("session-2") || denylist.IsSessionRevoked("") {
+		t.Error("expected only session-1 to be revoked")
+	}
+	time.Sleep(20 * time.Millisecond)
+	if denylist.IsSessionRevoked("session-1") {
+		t.Error("expected the entry to expire")
+	}
+
+	// Expired entries are swept on the next revocation
+	denylist.RevokeSession("session-2")
+	if len(denylist.sessions) != 1 {
+		t.Errorf("expected expired entries to be swept, got %v", denylist.sessions)
+	}
+}
+
+func TestIdentityInterceptorRejectsRevokedSessions(t *testing.T) {
+	denylist := NewTokenDenylistWithTTL(time.Minute)
+	interceptor := GrpcGatewayIdentityInterceptor(&Authenticator{
+		ClientID: "omniauth",
+		Verifier: TrustedGatewayVerifier{},
+		Denylist: denylist,
+	})
+	token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, keycloakClaims()).SignedString([]byte("secret"))
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+	info := &grpc.UnaryServerInfo{FullMethod: "/oauth.v1.AuthService/GetMe"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return nil, nil }
+
+	if _, err := interceptor(ctx, nil, info, handler); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	denylist.RevokeSession("session-1")
+	_, err := interceptor(ctx, nil, info, handler)
+	if status.Code(err) != codes.Unauthenticated || errorReason(t, err) != ReasonTokenRevoked {
+		t.Errorf("expected revoked token error, got %v", err)
+	}
+
+	// Revoking the subject denies tokens issued before the revocation
+	claims := keycloakClaims()
+	claims["sid"] = "session-2"
+	claims["iat"] = time.Now().Add(-time.Minute).Unix()
+	token, _ = jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("secret"))
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+	if _, err := interceptor(ctx, nil, info, handler); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	denylist.RevokeSubject("user-1", time.Now())
+	_, err = interceptor(ctx, nil, info, handler)
+	if status.Code(err) != codes.Unauthenticated || errorReason(t, err) != ReasonTokenRevoked {
+		t.Errorf("expected revoked token error, got %v", err)
+	}
+}
@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrorDomain is the google.rpc.ErrorInfo domain of errors raised by omniauth
+const ErrorDomain = "omniauth"
+
+// Reasons attached to Unauthenticated errors so clients can tell authentication failures apart
+const (
+	ReasonMissingToken       = "MISSING_TOKEN"
+	ReasonMalformedToken     = "MALFORMED_TOKEN"
+	ReasonInvalidSignature   = "INVALID_SIGNATURE"
+	ReasonTokenExpired       = "TOKEN_EXPIRED"
+	ReasonTokenNotYetValid   = "TOKEN_NOT_YET_VALID"
+	ReasonInvalidIssuer      = "INVALID_ISSUER"
+	ReasonInvalidAudience    = "INVALID_AUDIENCE"
+	ReasonUnauthorizedParty  = "UNAUTHORIZED_PARTY"
+	ReasonInvalidTokenClaims = "INVALID_TOKEN_CLAIMS"
+	ReasonTokenInactive      = "TOKEN_INACTIVE"
+)
+
+// ReasonIdentityProviderUnavailable is returned when Keycloak can't be reached
+const ReasonIdentityProviderUnavailable = "IDENTITY_PROVIDER_UNAVAILABLE"
+
+// StatusWithReason builds a gRPC status error carrying a google.rpc.ErrorInfo detail with the given reason
+func StatusWithReason(code codes.Code, reason, message string) error {
+	return StatusWithMetadata(code, reason, message, nil)
+}
+
+// StatusWithMetadata is StatusWithReason with additional ErrorInfo metadata
+func StatusWithMetadata(code codes.Code, reason, message string, metadata map[string]string) error {
+	st := status.New(code, message)
+	detailed, err := st.WithDetails(&errdetails.ErrorInfo{
+		Reason:   reason,
+		Domain:   ErrorDomain,
+		Metadata: metadata,
+	})
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
+// authError is a shorthand for Unauthenticated errors
+func authError(reason, message string) error {
+	return StatusWithReason(codes.Unauthenticated, reason, message)
+}
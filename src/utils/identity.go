@@ -0,0 +1,164 @@
+package utils
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// identityKey is the key used to store the caller's Identity in the context.
+const identityKey = contextKey("identity")
+
+// Identity is the authenticated caller of a request, built from the token claims
+type Identity struct {
+	UserID        string
+	Username      string
+	Email         string
+	EmailVerified bool
+	// AuthorizedParty is the client the token was issued to (azp)
+	AuthorizedParty string
+	RealmRoles      []string
+	// ClientRoles maps every client in resource_access to the caller's roles for it
+	ClientRoles map[string][]string
+	Groups      []string
+	Scopes      []string
+	SessionID   string
+	ACR         string
+	AMR         []string
+	// Claims holds every claim of the token, including custom ones
+	Claims jwt.MapClaims
+
+	// clientID is our own client, used by Roles
+	clientID string
+}
+
+// NewIdentity extracts the caller from verified claims. clientID is the client whose roles Roles returns.
+func NewIdentity(claims jwt.MapClaims, clientID string) *Identity {
+	identity := &Identity{
+		UserID:          claimString(claims, "sub"),
+		Username:        claimString(claims, "preferred_username"),
+		Email:           claimString(claims, "email"),
+		AuthorizedParty: claimString(claims, "azp"),
+		SessionID:       claimString(claims, "sid"),
+		ACR:             claimString(claims, "acr"),
+		Groups:          claimStrings(claims["groups"]),
+		AMR:             claimStrings(claims["amr"]),
+		ClientRoles:     make(map[string][]string),
+		Claims:          claims,
+		clientID:        clientID,
+	}
+	identity.EmailVerified, _ = claims["email_verified"].(bool)
+	if identity.SessionID == "" {
+		// Older Keycloak versions only set session_state
+		identity.SessionID = claimString(claims, "session_state")
+	}
+	if scope := claimString(claims, "scope"); scope != "" {
+		identity.Scopes = strings.Fields(scope)
+	}
+	if realmAccess, ok := claims["realm_access"].(map[string]interface{}); ok {
+		identity.RealmRoles = claimStrings(realmAccess["roles"])
+	}
+	if resAccess, ok := claims["resource_access"].(map[string]interface{}); ok {
+		for client, access := range resAccess {
+			if clientMap, ok := access.(map[string]interface{}); ok {
+				identity.ClientRoles[client] = claimStrings(clientMap["roles"])
+			}
+		}
+	}
+
+	return identity
+}
+
+// Roles returns the caller's roles for our own client
+func (i *Identity) Roles() []string {
+	return i.ClientRoles[i.clientID]
+}
+
+// HasRole reports whether the caller has role on client
+func (i *Identity) HasRole(client, role string) bool {
+	return slices.Contains(i.ClientRoles[client], role)
+}
+
+// HasClientRole reports whether the caller has role on our own client
+func (i *Identity) HasClientRole(role string) bool {
+	return i.HasRole(i.clientID, role)
+}
+
+// HasRealmRole reports whether the caller has the realm role
+func (i *Identity) HasRealmRole(role string) bool {
+	return slices.Contains(i.RealmRoles, role)
+}
+
+// HasScope reports whether the token was granted scope
+func (i *Identity) HasScope(scope string) bool {
+	return slices.Contains(i.Scopes, scope)
+}
+
+// InGroup reports whether the caller belongs to the group, by name or full path
+func (i *Identity) InGroup(group string) bool {
+	for _, g := range i.Groups {
+		if g == group || strings.TrimPrefix(g, "/") == strings.TrimPrefix(group, "/") {
+			return true
+		}
+	}
+	return false
+}
+
+// WithIdentity returns a new context carrying the caller's identity.
+func WithIdentity(ctx context.Context, identity *Identity) context.Context {
+	return context.WithValue(ctx, identityKey, identity)
+}
+
+// IdentityFromContext returns the caller's identity, if any. Unlike GetIdentity it doesn't treat
+// a missing identity as an error, e.g. for background jobs.
+func IdentityFromContext(ctx context.Context) (*Identity, bool) {
+	identity, ok := ctx.Value(identityKey).(*Identity)
+	if !ok || identity == nil || identity.UserID == "" {
+		return nil, false
+	}
+	return identity, true
+}
+
+// GetIdentity retrieves the caller's identity from the context.
+func GetIdentity(ctx context.Context) (*Identity, error) {
+	identity, ok := IdentityFromContext(ctx)
+	if !ok {
+		GetLogger(ctx).Error("identity not found in context")
+		return nil, status.Error(codes.Unauthenticated, "Unauthorized")
+	}
+	return identity, nil
+}
+
+// GetUser returns the caller's ID and roles for our own client.
+// Kept for handlers written before Identity, prefer GetIdentity.
+func GetUser(ctx context.Context) (string, []string, error) {
+	identity, err := GetIdentity(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+	return identity.UserID, identity.Roles(), nil
+}
+
+func claimString(claims jwt.MapClaims, name string) string {
+	value, _ := claims[name].(string)
+	return value
+}
+
+// claimStrings converts a JSON array claim into a string slice
+func claimStrings(value interface{}) []string {
+	list, ok := value.([]interface{})
+	if !ok {
+		return nil
+	}
+	var items []string
+	for _, item := range list {
+		if s, ok := item.(string); ok {
+			items = append(items, s)
+		}
+	}
+	return items
+}
@@ -0,0 +1,173 @@
+package utils
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func keycloakClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"sub":                "user-1",
+		"preferred_username": "alice",
+		"email":              "alice@example.com",
+		"email_verified":     true,
+		"azp":                "omniauth",
+		"sid":                "session-1",
+		"acr":                "1",
+		"amr":                []interface{}{"pwd", "otp"},
+		"scope":              "openid profile email",
+		"groups":             []interface{}{"/pro-group"},
+		"realm_access":       map[string]interface{}{"roles": []interface{}{"offline_access"}},
+		"resource_access": map[string]interface{}{
+			"omniauth": map[string]interface{}{"roles": []interface{}{"pro", "user"}},
+			"omndapi":  map[string]interface{}{"roles": []interface{}{"admin"}},
+		},
+	}
+}
+
+func TestNewIdentity(t *testing.T) {
+	identity := NewIdentity(keycloakClaims(), "omniauth")
+
+	if identity.UserID != "user-1" || identity.Username != "alice" || identity.Email != "alice@example.com" || !identity.EmailVerified {
+		t.Errorf("unexpected profile claims: %+v", identity)
+	}
+	if identity.SessionID != "session-1" || identity.ACR != "1" || !slices.Equal(identity.AMR, []string{"pwd", "otp"}) {
+		t.Errorf("unexpected session claims: %+v", identity)
+	}
+	if !slices.Equal(identity.Roles(), []string{"pro", "user"}) {
+		t.Errorf("unexpected roles %v", identity.Roles())
+	}
+	if !identity.HasRole("omndapi", "admin") || identity.HasClientRole("admin") {
+		t.Error("roles must be scoped to their client")
+	}
+	if !identity.HasRealmRole("offline_access") {
+		t.Error("expected realm role")
+	}
+	if !identity.HasScope("email") || identity.HasScope("phone") {
+		t.Error("unexpected scopes")
+	}
+	if !identity.InGroup("pro-group") || !identity.InGroup("/pro-group") || identity.InGroup("admin-group") {
+		t.Error("unexpected group membership")
+	}
+}
+
+func TestIdentityInterceptorInjectsIdentity(t *testing.T) {
+	interceptor := GrpcGatewayIdentityInterceptor(&Authenticator{
+		ClientID: "omniauth",
+		Verifier: TrustedGatewayVerifier{},
+	})
+	token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, keycloakClaims()).SignedString([]byte("secret"))
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+	info := &grpc.UnaryServerInfo{FullMethod: "/oauth.v1.AuthService/GetUser"}
+
+	_, err := interceptor(ctx, nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		identity, err := GetIdentity(ctx)
+		if err != nil {
+			t.Fatalf("expected identity in context: %v", err)
+		}
+		if identity.Username != "alice" {
+			t.Errorf("unexpected username %s", identity.Username)
+		}
+
+		userID, roles, err := GetUser(ctx)
+		if err != nil || userID != "user-1" || !slices.Equal(roles, []string{"pro", "user"}) {
+			t.Errorf("unexpected GetUser result %s %v %v", userID, roles, err)
+		}
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err = interceptor(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		t.Fatal("handler must not run without a token")
+		return nil, nil
+	})
+	if status.Code(err) != codes.Unauthenticated || errorReason(t, err) != ReasonMissingToken {
+		t.Errorf("expected missing token error, got %v", err)
+	}
+}
+
+func TestIdentityInterceptorPublicMethods(t *testing.T) {
+	const registerMethod = "/oauth.v1.AuthService/Register"
+	interceptor := GrpcGatewayIdentityInterceptor(&Authenticator{
+		ClientID:      "omniauth",
+		Verifier:      TrustedGatewayVerifier{},
+		PublicMethods: map[string]bool{registerMethod: true},
+	})
+	info := &grpc.UnaryServerInfo{FullMethod: registerMethod}
+
+	// Anonymous callers reach the handler without an identity
+	_, err := interceptor(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		if _, err := GetIdentity(ctx); err == nil {
+			t.Error("expected no identity for anonymous caller")
+		}
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// A token that is sent is still parsed
+	token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, keycloakClaims()).SignedString([]byte("secret"))
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+	_, err = interceptor(ctx, nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		if identity, err := GetIdentity(ctx); err != nil || identity.UserID != "user-1" {
+			t.Errorf("expected identity from token, got %v", err)
+		}
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// ... and rejected when it is garbage
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer garbage"))
+	_, err = interceptor(ctx, nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		t.Fatal("handler must not run with an invalid token")
+		return nil, nil
+	})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected Unauthenticated, got %v", err)
+	}
+}
+
+func TestIdentityInterceptorAnonymousMethods(t *testing.T) {
+	const refreshMethod = "/oauth.v1.AuthService/RefreshToken"
+	authz := NewAuthorizer()
+	if err := authz.LoadPolicies(authServiceInfo()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !authz.AnonymousMethods()[refreshMethod] || !authz.PublicMethods()[refreshMethod] {
+		t.Fatal("expected RefreshToken to be anonymous")
+	}
+
+	interceptor := GrpcGatewayIdentityInterceptor(&Authenticator{
+		ClientID:         "omniauth",
+		Verifier:         TrustedGatewayVerifier{},
+		PublicMethods:    authz.PublicMethods(),
+		AnonymousMethods: authz.AnonymousMethods(),
+	})
+
+	// A stale token must not fail the call that replaces it
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer garbage"))
+	_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: refreshMethod}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		if _, err := GetIdentity(ctx); err == nil {
+			t.Error("expected no identity for an anonymous method")
+		}
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := authz.Authorize(ctx, refreshMethod); err != nil {
+		t.Errorf("expected anonymous method to be allowed: %v", err)
+	}
+}
@@ -0,0 +1,133 @@
+package utils
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc/codes"
+)
+
+// Introspection environment variable keys
+const (
+	// AuthIntrospectMethods is a comma separated list of full method names that always introspect, "*" for all
+	AuthIntrospectMethods = "AUTH_INTROSPECT_METHODS"
+	// AuthIntrospectionCacheTTL bounds how long an introspection result is reused
+	AuthIntrospectionCacheTTL = "AUTH_INTROSPECTION_CACHE_TTL"
+)
+
+const (
+	defaultIntrospectionCacheTTL = 30 * time.Second
+	introspectionCacheSize       = 10000
+)
+
+type introspectionEntry struct {
+	claims    jwt.MapClaims
+	expiresAt time.Time
+}
+
+// IntrospectionVerifier asks Keycloak's introspection endpoint (RFC 7662) whether a token is still active.
+// Unlike local verification it notices logged out sessions and also accepts opaque tokens.
+type IntrospectionVerifier struct {
+	helper *CloakHelper
+	ttl    time.Duration
+
+	mu    sync.Mutex
+	cache map[string]introspectionEntry
+}
+
+func NewIntrospectionVerifier(helper *CloakHelper) (*IntrospectionVerifier, error) {
+	ttl := defaultIntrospectionCacheTTL
+	if value := os.Getenv(AuthIntrospectionCacheTTL); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", AuthIntrospectionCacheTTL, err)
+		}
+		ttl = parsed
+	}
+
+	return &IntrospectionVerifier{
+		helper: helper,
+		ttl:    ttl,
+		cache:  make(map[string]introspectionEntry),
+	}, nil
+}
+
+func (v *IntrospectionVerifier) Verify(ctx context.Context, tokenString string) (jwt.MapClaims, error) {
+	// Never keep raw tokens in memory longer than the request
+	sum := sha256.Sum256([]byte(tokenString))
+	key := hex.EncodeToString(sum[:])
+
+	claims, cached := v.lookup(key)
+	if !cached {
+		result, err := v.helper.IntrospectToken(ctx, tokenString)
+		if err != nil {
+			GetLogger(ctx).WithError(err).Error("token introspection failed")
+			return nil, StatusWithReason(codes.Unavailable, ReasonIdentityProviderUnavailable, "token introspection unavailable")
+		}
+		claims = jwt.MapClaims(result)
+		v.store(key, claims)
+	}
+
+	if active, _ := claims["active"].(bool); !active {
+		return nil, authError(ReasonTokenInactive, "token is no longer active")
+	}
+	return claims, nil
+}
+
+func (v *IntrospectionVerifier) lookup(key string) (jwt.MapClaims, bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	entry, ok := v.cache[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expiresAt) {
+		delete(v.cache, key)
+		return nil, false
+	}
+	return entry.claims, true
+}
+
+func (v *IntrospectionVerifier) store(key string, claims jwt.MapClaims) {
+	// Never cache past the token's own expiry
+	expiresAt := time.Now().Add(v.ttl)
+	if exp, err := claims.GetExpirationTime(); err == nil && exp != nil && exp.Before(expiresAt) {
+		expiresAt = exp.Time
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if len(v.cache) >= introspectionCacheSize {
+		now := time.Now()
+		for k, entry := range v.cache {
+			if now.After(entry.expiresAt) {
+				delete(v.cache, k)
+			}
+		}
+		// Still full, drop arbitrary entries rather than growing without bound
+		for k := range v.cache {
+			if len(v.cache) < introspectionCacheSize {
+				break
+			}
+			delete(v.cache, k)
+		}
+	}
+	v.cache[key] = introspectionEntry{claims: claims, expiresAt: expiresAt}
+}
+
+// MethodSet parses a comma separated list of full gRPC method names
+func MethodSet(value string) map[string]bool {
+	methods := make(map[string]bool)
+	for _, method := range splitList(value) {
+		methods[method] = true
+	}
+	return methods
+}
@@ -0,0 +1,133 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Nerzal/gocloak/v13"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeIntrospection serves the introspection endpoint, reporting tokens listed in active as active
+type fakeIntrospection struct {
+	mu       sync.Mutex
+	active   map[string]bool
+	requests int
+}
+
+func newIntrospectionHelper(t *testing.T, fake *fakeIntrospection) *CloakHelper {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/realms/"+testRealm+"/protocol/openid-connect/token/introspect", func(w http.ResponseWriter, r *http.Request) {
+		if id, secret, ok := r.BasicAuth(); !ok || id != "omniauth" || secret != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		r.ParseForm()
+		fake.mu.Lock()
+		fake.requests++
+		active := fake.active[r.FormValue("token")]
+		fake.mu.Unlock()
+
+		result := map[string]interface{}{"active": active}
+		if active {
+			result["sub"] = "user-1"
+			result["exp"] = time.Now().Add(time.Hour).Unix()
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(result)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return &CloakHelper{
+		Client:       gocloak.NewClient(server.URL),
+		URL:          server.URL,
+		Realm:        testRealm,
+		ClientID:     "omniauth",
+		ClientSecret: "secret",
+	}
+}
+
+func TestIntrospectionVerifier(t *testing.T) {
+	fake := &fakeIntrospection{active: map[string]bool{"good": true}}
+	verifier, err := NewIntrospectionVerifier(newIntrospectionHelper(t, fake))
+	if err != nil {
+		t.Fatalf("failed to create verifier: %v", err)
+	}
+
+	claims, err := verifier.Verify(context.Background(), "good")
+	if err != nil {
+		t.Fatalf("expected active token to verify: %v", err)
+	}
+	if claims["sub"] != "user-1" {
+		t.Errorf("unexpected sub %v", claims["sub"])
+	}
+
+	_, err = verifier.Verify(context.Background(), "revoked")
+	if status.Code(err) != codes.Unauthenticated || errorReason(t, err) != ReasonTokenInactive {
+		t.Errorf("expected inactive token to be rejected, got %v", err)
+	}
+
+	// Both results are served from the cache, keyed by the token hash
+	verifier.Verify(context.Background(), "good")
+	verifier.Verify(context.Background(), "revoked")
+	fake.mu.Lock()
+	requests := fake.requests
+	fake.mu.Unlock()
+	if requests != 2 {
+		t.Errorf("expected 2 introspection requests, got %d", requests)
+	}
+	if _, ok := verifier.cache["good"]; ok {
+		t.Error("raw token must not be used as cache key")
+	}
+
+	// Once the TTL passes the token is introspected again and revocation is noticed
+	verifier.ttl = 0
+	verifier.cache = make(map[string]introspectionEntry)
+	fake.mu.Lock()
+	fake.active["good"] = false
+	fake.mu.Unlock()
+	if _, err := verifier.Verify(context.Background(), "good"); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected revoked token to be rejected, got %v", err)
+	}
+}
+
+func TestIntrospectionVerifierUnavailable(t *testing.T) {
+	helper := newIntrospectionHelper(t, &fakeIntrospection{})
+	helper.ClientSecret = "wrong"
+	verifier, _ := NewIntrospectionVerifier(helper)
+
+	_, err := verifier.Verify(context.Background(), "good")
+	if status.Code(err) != codes.Unavailable {
+		t.Errorf("expected Unavailable, got %v", err)
+	}
+}
+
+func TestAuthenticatorVerifierFor(t *testing.T) {
+	local := TrustedGatewayVerifier{}
+	introspector := &IntrospectionVerifier{}
+	auth := &Authenticator{
+		Verifier:          local,
+		Introspector:      introspector,
+		IntrospectMethods: MethodSet("/oauth.v1.AuthService/DeleteUser, /oauth.v1.AuthService/UpdateMe"),
+	}
+
+	if auth.verifierFor("/oauth.v1.AuthService/DeleteUser") != introspector {
+		t.Error("expected sensitive method to introspect")
+	}
+	if auth.verifierFor("/oauth.v1.AuthService/GetUser") != local {
+		t.Error("expected cheap read to verify locally")
+	}
+
+	auth.IntrospectMethods = MethodSet("*")
+	if auth.verifierFor("/oauth.v1.AuthService/GetUser") != introspector {
+		t.Error("expected wildcard to introspect every method")
+	}
+}
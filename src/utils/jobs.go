@@ -0,0 +1,206 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Job is a task run periodically in the background
+type Job struct {
+	Name string
+	// Interval between runs, each run is delayed by up to Jitter more so replicas don't run in lockstep
+	Interval time.Duration
+	Jitter   time.Duration
+	// Timeout bounds a run and how long its lock is held, defaults to Interval
+	Timeout time.Duration
+	Run     func(ctx context.Context) error
+}
+
+// Locker makes sure only one replica runs a job at a time
+type Locker interface {
+	// TryLock takes the named lock for at most ttl. ok is false if someone else holds it.
+	TryLock(ctx context.Context, name string, ttl time.Duration) (unlock func(), ok bool, err error)
+}
+
+// MemoryLocker is a Locker for a single replica
+type MemoryLocker struct {
+	mu   sync.Mutex
+	held map[string]time.Time
+}
+
+func NewMemoryLocker() *MemoryLocker {
+	return &MemoryLocker{held: make(map[string]time.Time)}
+}
+
+func (l *MemoryLocker) TryLock(ctx context.Context, name string, ttl time.Duration) (func(), bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if expiresAt, ok := l.held[name]; ok && now.Before(expiresAt) {
+		return nil, false, nil
+	}
+	expiresAt := now.Add(ttl)
+	l.held[name] = expiresAt
+
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		// Don't release a lock that expired and was taken by someone else
+		if l.held[name] == expiresAt {
+			delete(l.held, name)
+		}
+	}, true, nil
+}
+
+// JobStats are the metrics of a job
+type JobStats struct {
+	Name string `json:"name"`
+	// Runs counts completed runs, Failures those that returned an error,
+	// Skipped the runs that didn't take place because another replica held the lock
+	Runs         uint64        `json:"runs"`
+	Failures     uint64        `json:"failures"`
+	Skipped      uint64        `json:"skipped"`
+	LastRun      time.Time     `json:"last_run"`
+	LastSuccess  time.Time     `json:"last_success"`
+	LastDuration time.Duration `json:"last_duration"`
+	LastError    string        `json:"last_error,omitempty"`
+}
+
+// JobRunner runs jobs periodically until its context is done
+type JobRunner struct {
+	locker Locker
+	jobs   []Job
+
+	mu    sync.Mutex
+	stats map[string]*JobStats
+}
+
+func NewJobRunner(locker Locker) *JobRunner {
+	return &JobRunner{
+		locker: locker,
+		stats:  make(map[string]*JobStats),
+	}
+}
+
+// Add registers a job, it must be called before Start.
+// Jobs without a positive interval or with a negative timeout are rejected, they would run back to back
+// with an expired context. A zero timeout defaults to the interval.
+func (r *JobRunner) Add(job Job) error {
+	if job.Interval <= 0 {
+		return fmt.Errorf("job %s needs a positive interval, got %s", job.Name, job.Interval)
+	}
+	if job.Timeout < 0 {
+		return fmt.Errorf("job %s needs a non-negative timeout, got %s", job.Name, job.Timeout)
+	}
+	if job.Timeout == 0 {
+		job.Timeout = job.Interval
+	}
+	r.jobs = append(r.jobs, job)
+	r.stats[job.Name] = &JobStats{Name: job.Name}
+	return nil
+}
+
+// Start runs every job in its own goroutine until ctx is done
+func (r *JobRunner) Start(ctx context.Context) {
+	for _, job := range r.jobs {
+		go r.loop(ctx, job)
+	}
+}
+
+func (r *JobRunner) loop(ctx context.Context, job Job) {
+	// The first run only waits for the jitter
+	delay := jitter(job.Jitter)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		r.RunOnce(ctx, job)
+		delay = job.Interval + jitter(job.Jitter)
+	}
+}
+
+// RunOnce runs job now if its lock is free
+func (r *JobRunner) RunOnce(ctx context.Context, job Job) {
+	logger := GetLogger(ctx).WithField("job", job.Name)
+
+	unlock, ok, err := r.locker.TryLock(ctx, job.Name, job.Timeout)
+	if err != nil {
+		logger.WithError(err).Error("failed to take job lock")
+		r.record(job.Name, func(stats *JobStats) { stats.Skipped++ })
+		return
+	}
+	if !ok {
+		logger.Debug("job is running elsewhere, skipping")
+		r.record(job.Name, func(stats *JobStats) { stats.Skipped++ })
+		return
+	}
+	defer unlock()
+
+	runCtx, cancel := context.WithTimeout(WithLogger(ctx, logger), job.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	err = runJob(runCtx, job)
+	duration := time.Since(start)
+
+	r.record(job.Name, func(stats *JobStats) {
+		stats.Runs++
+		stats.LastRun = start
+		stats.LastDuration = duration
+		stats.LastError = ""
+		if err != nil {
+			stats.Failures++
+			stats.LastError = err.Error()
+		} else {
+			stats.LastSuccess = start
+		}
+	})
+	if err != nil {
+		logger.WithError(err).Error("job failed")
+		return
+	}
+	logger.Debugf("job finished in %s", duration)
+}
+
+// runJob runs job, turning a panic into an error so one bad run doesn't stop the binary
+func runJob(ctx context.Context, job Job) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("job panicked: %v", recovered)
+		}
+	}()
+	return job.Run(ctx)
+}
+
+func (r *JobRunner) record(name string, update func(stats *JobStats)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	update(r.stats[name])
+}
+
+// Stats returns the metrics of every job, sorted by name
+func (r *JobRunner) Stats() []JobStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stats := make([]JobStats, 0, len(r.stats))
+	for _, s := range r.stats {
+		stats = append(stats, *s)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Name < stats[j].Name })
+	return stats
+}
+
+func jitter(max time.Duration) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+	return rand.N(max)
+}
@@ -0,0 +1,142 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestJobRunnerRunOnce(t *testing.T) {
+	runner := NewJobRunner(NewMemoryLocker())
+	fail := errors.New("boom")
+	calls := 0
+	job := Job{Name: "test", Interval: time.Minute, Run: func(ctx context.Context) error {
+		calls++
+		if _, ok := ctx.Deadline(); !ok {
+			t.Error("expected the run to have a deadline")
+		}
+		switch calls {
+		case 1:
+			return nil
+		case 2:
+			return fail
+		default:
+			panic("bad job")
+		}
+	}}
+	if err := runner.Add(job); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		runner.RunOnce(context.Background(), job)
+	}
+
+	stats := runner.Stats()
+	if len(stats) != 1 {
+		t.Fatalf("expected 1 job, got %d", len(stats))
+	}
+	if stats[0].Runs != 3 || stats[0].Failures != 2 || stats[0].Skipped != 0 {
+		t.Errorf("unexpected stats %+v", stats[0])
+	}
+	if stats[0].LastError != "job panicked: bad job" {
+		t.Errorf("unexpected last error %q", stats[0].LastError)
+	}
+	if stats[0].LastSuccess.IsZero() || stats[0].LastSuccess.After(stats[0].LastRun) {
+		t.Errorf("unexpected last success %v, last run %v", stats[0].LastSuccess, stats[0].LastRun)
+	}
+}
+
+func TestJobRunnerSkipsLockedJob(t *testing.T) {
+	locker := NewMemoryLocker()
+	runner := NewJobRunner(locker)
+	job := Job{Name: "test", Interval: time.Minute, Run: func(ctx context.Context) error {
+		t.Error("locked job must not run")
+		return nil
+	}}
+	if err := runner.Add(job); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	unlock, ok, err := locker.TryLock(context.Background(), job.Name, time.Minute)
+	if err != nil || !ok {
+		t.Fatalf("expected to take the lock, got %v %v", ok, err)
+	}
+	runner.RunOnce(context.Background(), job)
+	if stats := runner.Stats()[0]; stats.Skipped != 1 || stats.Runs != 0 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+
+	unlock()
+	if _, ok, _ := locker.TryLock(context.Background(), job.Name, time.Minute); !ok {
+		t.Error("expected the lock to be free after unlock")
+	}
+}
+
+func TestMemoryLockerExpires(t *testing.T) {
+	locker := NewMemoryLocker()
+	ctx := context.Background()
+
+	unlock, ok, _ := locker.TryLock(ctx, "test", time.Millisecond)
+	if !ok {
+		t.Fatal("expected to take the lock")
+	}
+	time.Sleep(5 * time.Millisecond)
+	if _, ok, _ := locker.TryLock(ctx, "test", time.Minute); !ok {
+		t.Fatal("expected the expired lock to be taken")
+	}
+	// The expired holder must not release the new one
+	unlock()
+	if _, ok, _ := locker.TryLock(ctx, "test", time.Minute); ok {
+		t.Error("expected the lock to still be held")
+	}
+}
+
+func TestJobRunnerStart(t *testing.T) {
+	runner := NewJobRunner(NewMemoryLocker())
+	var runs atomic.Int32
+	ran := make(chan struct{}, 10)
+	err := runner.Add(Job{Name: "test", Interval: time.Millisecond, Run: func(ctx context.Context) error {
+		runs.Add(1)
+		ran <- struct{}{}
+		return nil
+	}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	runner.Start(ctx)
+	for i := 0; i < 2; i++ {
+		select {
+		case <-ran:
+		case <-time.After(time.Second):
+			t.Fatal("job did not run")
+		}
+	}
+	cancel()
+
+	time.Sleep(20 * time.Millisecond)
+	stopped := runs.Load()
+	time.Sleep(20 * time.Millisecond)
+	if runs.Load() != stopped {
+		t.Error("expected the job to stop after cancel")
+	}
+}
+
+func TestJobRunnerRejectsInvalidSchedules(t *testing.T) {
+	runner := NewJobRunner(NewMemoryLocker())
+	for _, interval := range []time.Duration{0, -time.Minute} {
+		if err := runner.Add(Job{Name: "test", Interval: interval}); err == nil {
+			t.Errorf("expected interval %s to be rejected", interval)
+		}
+	}
+	if err := runner.Add(Job{Name: "test", Interval: time.Minute, Timeout: -time.Second}); err == nil {
+		t.Error("expected a negative timeout to be rejected")
+	}
+	if len(runner.Stats()) != 0 {
+		t.Error("expected rejected jobs not to be registered")
+	}
+}
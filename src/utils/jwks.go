@@ -0,0 +1,232 @@
+package utils
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/Nerzal/gocloak/v13"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/singleflight"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// jwksRefreshInterval bounds how long a fetched key set is used before it is reloaded.
+	jwksRefreshInterval = 10 * time.Minute
+	// jwksMinRefreshInterval throttles reloads triggered by tokens with an unknown kid.
+	jwksMinRefreshInterval = 30 * time.Second
+	// jwksFetchTimeout bounds a shared fetch, which doesn't end when its callers give up
+	jwksFetchTimeout = 10 * time.Second
+)
+
+// signingMethods are the algorithms Keycloak realm keys can sign with.
+var signingMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+
+// TokenVerifier turns a raw bearer token into its claims.
+type TokenVerifier interface {
+	Verify(ctx context.Context, tokenString string) (jwt.MapClaims, error)
+}
+
+// TrustedGatewayVerifier parses claims without verifying the signature.
+// Only use it when every request is guaranteed to pass through a gateway that already verified the token.
+type TrustedGatewayVerifier struct{}
+
+func (TrustedGatewayVerifier) Verify(ctx context.Context, tokenString string) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(tokenString, claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// JWKSVerifier verifies token signatures against the realm's JSON Web Key Set.
+// Keys are cached by kid and reloaded when a token references a key we haven't seen (key rotation).
+type JWKSVerifier struct {
+	helper *CloakHelper
+	parser *jwt.Parser
+
+	mu        sync.RWMutex
+	keys      map[string]interface{}
+	fetchedAt time.Time
+	group     singleflight.Group
+
+	refreshInterval    time.Duration
+	minRefreshInterval time.Duration
+}
+
+func NewJWKSVerifier(helper *CloakHelper) *JWKSVerifier {
+	// Only the signature is checked here, claims are validated by ClaimsValidator
+	return &JWKSVerifier{
+		helper:             helper,
+		parser:             jwt.NewParser(jwt.WithValidMethods(signingMethods), jwt.WithoutClaimsValidation()),
+		refreshInterval:    jwksRefreshInterval,
+		minRefreshInterval: jwksMinRefreshInterval,
+	}
+}
+
+func (v *JWKSVerifier) Verify(ctx context.Context, tokenString string) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+	_, err := v.parser.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		if kid == "" {
+			return nil, errors.New("token header has no kid")
+		}
+		return v.key(ctx, kid)
+	})
+	if err != nil {
+		// Unreachable realm keys are reported as they are, not as a bad signature
+		var keyErr interface{ GRPCStatus() *status.Status }
+		if errors.As(err, &keyErr) {
+			return nil, keyErr.GRPCStatus().Err()
+		}
+		return nil, err
+	}
+	return claims, nil
+}
+
+// key returns the public key for kid, reloading the key set when it is stale or kid is unknown.
+func (v *JWKSVerifier) key(ctx context.Context, kid string) (interface{}, error) {
+	v.mu.RLock()
+	key, ok := v.keys[kid]
+	age := time.Since(v.fetchedAt)
+	v.mu.RUnlock()
+
+	if ok && age < v.refreshInterval {
+		return key, nil
+	}
+	if !ok && age < v.minRefreshInterval {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	if err := v.refresh(ctx); err != nil {
+		if ok {
+			// Keep using the cached key while Keycloak is unreachable
+			GetLogger(ctx).WithError(err).Warn("failed to refresh realm keys, using cached key")
+			return key, nil
+		}
+		if ctx.Err() != nil {
+			return nil, KeycloakError(ctx, err)
+		}
+		GetLogger(ctx).WithError(err).Error("failed to fetch realm keys")
+		return nil, StatusWithReason(codes.Unavailable, ReasonIdentityProviderUnavailable, "realm keys unavailable")
+	}
+
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	if key, ok := v.keys[kid]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// refresh reloads the key set, collapsing concurrent reloads into one request.
+// One caller giving up must not fail the others, so the fetch is detached and each caller only stops waiting.
+func (v *JWKSVerifier) refresh(ctx context.Context) error {
+	fetch := v.group.DoChan("jwks", func() (interface{}, error) {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), jwksFetchTimeout)
+		defer cancel()
+		certs, err := v.helper.GetRealmCerts(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		keys := make(map[string]interface{})
+		if certs.Keys != nil {
+			for _, jwk := range *certs.Keys {
+				if jwk.Kid == nil || (jwk.Use != nil && *jwk.Use != "sig") {
+					continue
+				}
+				key, err := parseJWK(jwk)
+				if err != nil {
+					logrus.WithError(err).Warnf("skipping realm key %s", *jwk.Kid)
+					continue
+				}
+				keys[*jwk.Kid] = key
+			}
+		}
+
+		v.mu.Lock()
+		v.keys = keys
+		v.fetchedAt = time.Now()
+		v.mu.Unlock()
+		return nil, nil
+	})
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case result := <-fetch:
+		return result.Err
+	}
+}
+
+// parseJWK converts an RSA or EC JSON Web Key into a public key
+func parseJWK(jwk gocloak.CertResponseKey) (interface{}, error) {
+	kty := ""
+	if jwk.Kty != nil {
+		kty = *jwk.Kty
+	}
+
+	switch kty {
+	case "RSA":
+		n, err := decodeJWKInt(jwk.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid modulus: %w", err)
+		}
+		e, err := decodeJWKInt(jwk.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exponent: %w", err)
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch safeDeref(jwk.Crv) {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", safeDeref(jwk.Crv))
+		}
+		x, err := decodeJWKInt(jwk.X)
+		if err != nil {
+			return nil, fmt.Errorf("invalid x coordinate: %w", err)
+		}
+		y, err := decodeJWKInt(jwk.Y)
+		if err != nil {
+			return nil, fmt.Errorf("invalid y coordinate: %w", err)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", kty)
+	}
+}
+
+func decodeJWKInt(value *string) (*big.Int, error) {
+	if value == nil || *value == "" {
+		return nil, errors.New("missing value")
+	}
+	b, err := base64.RawURLEncoding.DecodeString(*value)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+func safeDeref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,232 @@
+package utils
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Nerzal/gocloak/v13"
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const testRealm = "omni"
+
+// fakeRealm serves a JWKS endpoint whose keys can be rotated during a test.
+type fakeRealm struct {
+	server *httptest.Server
+
+	mu       sync.Mutex
+	keys     map[string]*rsa.PrivateKey
+	requests int
+	// delay slows down the certs endpoint, failing makes it answer 500
+	delay   time.Duration
+	failing bool
+}
+
+func newFakeRealm(t *testing.T) *fakeRealm {
+	t.Helper()
+	realm := &fakeRealm{keys: make(map[string]*rsa.PrivateKey)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/realms/"+testRealm+"/protocol/openid-connect/certs", func(w http.ResponseWriter, r *http.Request) {
+		realm.mu.Lock()
+		delay := realm.delay
+		realm.mu.Unlock()
+		time.Sleep(delay)
+
+		realm.mu.Lock()
+		defer realm.mu.Unlock()
+		realm.requests++
+		if realm.failing {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		keys := []map[string]string{}
+		for kid, key := range realm.keys {
+			keys = append(keys, map[string]string{
+				"kid": kid,
+				"kty": "RSA",
+				"alg": "RS256",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": keys})
+	})
+	realm.server = httptest.NewServer(mux)
+	t.Cleanup(realm.server.Close)
+	return realm
+}
+
+func (r *fakeRealm) addKey(t *testing.T, kid string) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	r.mu.Lock()
+	r.keys[kid] = key
+	r.mu.Unlock()
+	return key
+}
+
+func (r *fakeRealm) requestCount() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.requests
+}
+
+func (r *fakeRealm) helper() *CloakHelper {
+	return &CloakHelper{
+		Client: gocloak.NewClient(r.server.URL),
+		URL:    r.server.URL,
+		Realm:  testRealm,
+	}
+}
+
+func signToken(t *testing.T, key *rsa.PrivateKey, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	return signed
+}
+
+func TestJWKSVerifierAcceptsValidSignature(t *testing.T) {
+	realm := newFakeRealm(t)
+	key := realm.addKey(t, "k1")
+	verifier := NewJWKSVerifier(realm.helper())
+
+	token := signToken(t, key, "k1", jwt.MapClaims{"sub": "user-1", "exp": time.Now().Add(time.Minute).Unix()})
+	claims, err := verifier.Verify(context.Background(), token)
+	if err != nil {
+		t.Fatalf("expected token to verify: %v", err)
+	}
+	if claims["sub"] != "user-1" {
+		t.Errorf("unexpected sub %v", claims["sub"])
+	}
+
+	// Second verification is served from the cached key set
+	if _, err := verifier.Verify(context.Background(), token); err != nil {
+		t.Fatalf("expected token to verify: %v", err)
+	}
+	if realm.requestCount() != 1 {
+		t.Errorf("expected 1 JWKS request, got %d", realm.requestCount())
+	}
+}
+
+func TestJWKSVerifierRejectsForgedTokens(t *testing.T) {
+	realm := newFakeRealm(t)
+	realm.addKey(t, "k1")
+	verifier := NewJWKSVerifier(realm.helper())
+
+	attacker, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	forged := signToken(t, attacker, "k1", jwt.MapClaims{"sub": "admin"})
+	if _, err := verifier.Verify(context.Background(), forged); err == nil {
+		t.Error("expected forged signature to be rejected")
+	}
+
+	unsigned := jwt.NewWithClaims(jwt.SigningMethodNone, jwt.MapClaims{"sub": "admin"})
+	unsigned.Header["kid"] = "k1"
+	none, _ := unsigned.SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if _, err := verifier.Verify(context.Background(), none); err == nil {
+		t.Error("expected alg=none token to be rejected")
+	}
+}
+
+func TestJWKSVerifierRefetchesOnRotation(t *testing.T) {
+	realm := newFakeRealm(t)
+	oldKey := realm.addKey(t, "k1")
+	verifier := NewJWKSVerifier(realm.helper())
+	verifier.minRefreshInterval = 0
+
+	if _, err := verifier.Verify(context.Background(), signToken(t, oldKey, "k1", jwt.MapClaims{"sub": "u"})); err != nil {
+		t.Fatalf("expected token to verify: %v", err)
+	}
+
+	// Keycloak rotates to a new key; tokens signed with it must be accepted without a restart
+	newKey := realm.addKey(t, "k2")
+	if _, err := verifier.Verify(context.Background(), signToken(t, newKey, "k2", jwt.MapClaims{"sub": "u"})); err != nil {
+		t.Fatalf("expected token signed by rotated key to verify: %v", err)
+	}
+	if realm.requestCount() != 2 {
+		t.Errorf("expected 2 JWKS requests, got %d", realm.requestCount())
+	}
+}
+
+func TestJWKSVerifierThrottlesUnknownKid(t *testing.T) {
+	realm := newFakeRealm(t)
+	realm.addKey(t, "k1")
+	verifier := NewJWKSVerifier(realm.helper())
+
+	attacker, _ := rsa.GenerateKey(rand.Reader, 2048)
+	for i := 0; i < 5; i++ {
+		if _, err := verifier.Verify(context.Background(), signToken(t, attacker, "unknown", jwt.MapClaims{"sub": "u"})); err == nil {
+			t.Fatal("expected unknown kid to be rejected")
+		}
+	}
+	if realm.requestCount() != 1 {
+		t.Errorf("expected unknown kids to trigger a single JWKS request, got %d", realm.requestCount())
+	}
+}
+
+func TestJWKSVerifierFetchOutlivesCaller(t *testing.T) {
+	realm := newFakeRealm(t)
+	key := realm.addKey(t, "k1")
+	realm.delay = 100 * time.Millisecond
+	verifier := NewJWKSVerifier(realm.helper())
+	token := signToken(t, key, "k1", jwt.MapClaims{"sub": "user-1"})
+
+	// The caller starting the shared fetch gives up, the other waiter still verifies its token
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	started := make(chan error, 1)
+	go func() {
+		_, err := verifier.Verify(ctx, token)
+		started <- err
+	}()
+	time.Sleep(5 * time.Millisecond)
+	if _, err := verifier.Verify(context.Background(), token); err != nil {
+		t.Errorf("expected the waiter's token to verify, got %v", err)
+	}
+	if err := <-started; status.Code(err) != codes.DeadlineExceeded {
+		t.Errorf("expected the first caller to stop at its deadline, got %v", err)
+	}
+	if realm.requestCount() != 1 {
+		t.Errorf("expected 1 JWKS request, got %d", realm.requestCount())
+	}
+}
+
+func TestJWKSVerifierReportsUnavailableRealm(t *testing.T) {
+	realm := newFakeRealm(t)
+	key := realm.addKey(t, "k1")
+	realm.failing = true
+	auth := &Authenticator{ClientID: "omniauth", Verifier: NewJWKSVerifier(realm.helper())}
+
+	// Without cached keys a Keycloak outage must not look like a forged token
+	token := signToken(t, key, "k1", jwt.MapClaims{"sub": "user-1"})
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+	_, err := auth.Authenticate(ctx, getUserMethod)
+	if status.Code(err) != codes.Unavailable || errorReason(t, err) != ReasonIdentityProviderUnavailable {
+		t.Errorf("expected identity provider unavailable, got %v", err)
+	}
+}
@@ -3,96 +3,126 @@ package utils
 
 import (
 	"context"
+	"errors"
 	"strings"
+	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 )
 
-type ContextKey string
+// Authenticator resolves the caller of a request from its bearer token
+type Authenticator struct {
+	// ClientID is our own client, whose roles Identity.Roles returns
+	ClientID  string
+	Verifier  TokenVerifier
+	Validator *ClaimsValidator
 
-const (
-	UserIDKey    ContextKey = "user_id"
-	UserRolesKey ContextKey = "user_roles"
-)
+	// Introspector replaces Verifier for IntrospectMethods, "*" matches every method
+	Introspector      TokenVerifier
+	IntrospectMethods map[string]bool
 
-// IdentityInterceptor parses claims without verifying signature (Gateway trusted)
-func GrpcGatewayIdentityInterceptor(clientID string) grpc.UnaryServerInterceptor {
-	return func(
-		ctx context.Context,
-		req interface{},
-		info *grpc.UnaryServerInfo,
-		handler grpc.UnaryHandler,
-	) (interface{}, error) {
-		// 1. Extract Token
-		md, ok := metadata.FromIncomingContext(ctx)
-		if !ok {
-			return nil, status.Error(codes.Unauthenticated, "missing metadata")
-		}
-		values := md["authorization"]
-		if len(values) == 0 {
-			return nil, status.Error(codes.Unauthenticated, "missing auth header")
-		}
-		tokenString := strings.TrimPrefix(values[0], "Bearer ")
+	// PublicMethods don't require a token, but a token that is sent is still verified
+	PublicMethods map[string]bool
+	// AnonymousMethods ignore the token altogether, so a stale one doesn't fail e.g. a token refresh
+	AnonymousMethods map[string]bool
 
-		// 2. Parse Claims (Unverified because Gateway already verified it)
-		parser := jwt.NewParser()
-		token, _, err := parser.ParseUnverified(tokenString, jwt.MapClaims{})
-		if err != nil {
-			return nil, status.Errorf(codes.Internal, "failed to parse token: %v", err)
-		}
+	// Denylist rejects tokens of revoked sessions and subjects, nil disables the check
+	Denylist *TokenDenylist
+}
 
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			return nil, status.Error(codes.Internal, "invalid claims structure")
-		}
+// verifierFor picks introspection for sensitive methods and local verification for the rest
+func (a *Authenticator) verifierFor(fullMethod string) TokenVerifier {
+	if a.Introspector != nil && (a.IntrospectMethods[fullMethod] || a.IntrospectMethods["*"]) {
+		return a.Introspector
+	}
+	return a.Verifier
+}
 
-		// 3. Extract User ID
-		userID, _ := claims["sub"].(string)
+// Authenticate verifies the bearer token of an incoming call and returns a context carrying the caller's Identity.
+// Anonymous calls to public methods and every call to anonymous methods return ctx without an Identity.
+func (a *Authenticator) Authenticate(ctx context.Context, fullMethod string) (context.Context, error) {
+	if a.AnonymousMethods[fullMethod] {
+		return ctx, nil
+	}
 
-		// 4. Extract Roles for THIS specific Client
-		var roles []string
-		if resAccess, ok := claims["resource_access"].(map[string]interface{}); ok {
-			if clientMap, ok := resAccess[clientID].(map[string]interface{}); ok {
-				if roleList, ok := clientMap["roles"].([]interface{}); ok {
-					for _, r := range roleList {
-						if rStr, ok := r.(string); ok {
-							roles = append(roles, rStr)
-						}
-					}
-				}
-			}
+	// 1. Extract Token
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md["authorization"]
+	if len(values) == 0 {
+		if a.PublicMethods[fullMethod] {
+			return ctx, nil
 		}
+		return nil, authError(ReasonMissingToken, "missing auth header")
+	}
+	tokenString := strings.TrimPrefix(values[0], "Bearer ")
 
-		// 5. Inject into Context
-		ctx = context.WithValue(ctx, UserIDKey, userID)
-		ctx = context.WithValue(ctx, UserRolesKey, roles)
+	// 2. Verify Token and Parse Claims
+	claims, err := a.verifierFor(fullMethod).Verify(ctx, tokenString)
+	if err != nil {
+		GetLogger(ctx).WithError(err).Warn("rejected bearer token")
+		if _, ok := status.FromError(err); ok {
+			return nil, err
+		}
+		if errors.Is(err, jwt.ErrTokenMalformed) {
+			return nil, authError(ReasonMalformedToken, "malformed token")
+		}
+		return nil, authError(ReasonInvalidSignature, "invalid token signature")
+	}
+	if a.Validator != nil {
+		if err := a.Validator.Validate(claims); err != nil {
+			GetLogger(ctx).WithError(err).Warn("rejected token claims")
+			return nil, err
+		}
+	}
 
-		return handler(ctx, req)
+	identity := NewIdentity(claims, a.ClientID)
+	if a.Denylist != nil {
+		var issuedAt time.Time
+		if iat, err := claims.GetIssuedAt(); err == nil && iat != nil {
+			issuedAt = iat.Time
+		}
+		if a.Denylist.IsRevoked(identity.SessionID, identity.UserID, issuedAt) {
+			GetLogger(ctx).WithFields(logrus.Fields{"sid": identity.SessionID, "sub": identity.UserID}).Warn("rejected revoked token")
+			return nil, authError(ReasonTokenRevoked, "token was revoked")
+		}
 	}
-}
 
-func GetUser(ctx context.Context) (string, []string, error) {
-	userIDVal := ctx.Value(UserIDKey)
-	rolesVal := ctx.Value(UserRolesKey)
+	// 3. Inject the caller into the Context
+	return WithIdentity(ctx, identity), nil
+}
 
-	// 检查用户ID是否存在
-	userID, ok := userIDVal.(string)
-	if !ok || userID == "" {
-		logrus.Errorf("User ID not found in context %v", userID)
-		return "", nil, status.Error(codes.Unauthenticated, "Unauthorized")
+// GrpcGatewayIdentityInterceptor authenticates the bearer token and injects the caller into the context
+func GrpcGatewayIdentityInterceptor(auth *Authenticator) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		ctx, err := auth.Authenticate(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
 	}
+}
 
-	// 检查角色是否存在
-	roles, ok := rolesVal.([]string)
-	if !ok {
-		logrus.Errorf("User roles not found in context %v", roles)
-		return "", nil, status.Error(codes.Unauthenticated, "Unauthorized")
+// GrpcGatewayIdentityStreamInterceptor is the streaming counterpart of GrpcGatewayIdentityInterceptor
+func GrpcGatewayIdentityStreamInterceptor(auth *Authenticator) grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		stream grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		ctx, err := auth.Authenticate(stream.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, WrapServerStream(ctx, stream))
 	}
-
-	return userID, roles, nil
 }
@@ -4,8 +4,14 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/Nerzal/gocloak/v13"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/sync/singleflight"
 )
 
 // PublicUserData 表示公开的用户信息
@@ -17,6 +23,9 @@ type PublicUserData struct {
 	Email     string `json:"email"`
 }
 
+// clientRoleLookups bounds concurrent role lookups of GetEffectiveClientRoles
+const clientRoleLookups = 4
+
 // Keycloak 环境变量键常量
 const (
 	KeycloakURL          = "KEYCLOAK_URL"
@@ -27,9 +36,17 @@ const (
 
 type CloakHelper struct {
 	Client       *gocloak.GoCloak
+	URL          string
 	Realm        string
 	ClientID     string
 	ClientSecret string
+
+	// Cached service account token, see ServiceToken
+	tokenMu        sync.RWMutex
+	token          string
+	tokenRefreshAt time.Time
+	tokenExpiresAt time.Time
+	tokenGroup     singleflight.Group
 }
 
 func NewCloakHelper() *CloakHelper {
@@ -55,6 +72,7 @@ func NewCloakHelper() *CloakHelper {
 
 	return &CloakHelper{
 		Client:       gocloak.NewClient(url),
+		URL:          strings.TrimRight(url, "/"),
 		Realm:        realm,
 		ClientID:     clientID,
 		ClientSecret: secret,
@@ -63,18 +81,386 @@ func NewCloakHelper() *CloakHelper {
 
 // GetUserProfile fetches a user by ID using the Service Account token
 func (s *CloakHelper) GetUserProfile(ctx context.Context, targetUserID string) (*gocloak.User, error) {
-	// 1. Login as Service Account (Client Credentials)
-	// Optimization Note: You should cache this token and only refresh when it expires.
-	token, err := s.Client.LoginClient(ctx, s.ClientID, s.ClientSecret, s.Realm)
+	var user *gocloak.User
+	err := s.WithServiceToken(ctx, func(token string) error {
+		var err error
+		user, err = s.Client.GetUserByID(ctx, token, s.Realm, targetUserID)
+		return err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to login as service account: %w", err)
+		return nil, fmt.Errorf("failed to get user %s: %w", targetUserID, err)
 	}
 
-	// 2. Use the Admin token to fetch the specific user
-	user, err := s.Client.GetUserByID(ctx, token.AccessToken, s.Realm, targetUserID)
+	return user, nil
+}
+
+// UpdateUser replaces a user's representation, fields left nil are kept by Keycloak
+func (s *CloakHelper) UpdateUser(ctx context.Context, user gocloak.User) error {
+	err := s.WithServiceToken(ctx, func(token string) error {
+		return s.Client.UpdateUser(ctx, token, s.Realm, user)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user %s: %w", targetUserID, err)
+		return fmt.Errorf("failed to update user %s: %w", safeDeref(user.ID), err)
 	}
 
-	return user, nil
+	return nil
+}
+
+// SendVerifyEmail emails the user a link to verify their email address
+func (s *CloakHelper) SendVerifyEmail(ctx context.Context, targetUserID string) error {
+	err := s.WithServiceToken(ctx, func(token string) error {
+		return s.Client.SendVerifyEmail(ctx, token, targetUserID, s.Realm)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send verify email to user %s: %w", targetUserID, err)
+	}
+
+	return nil
+}
+
+// CreateUser creates a user and returns its ID
+func (s *CloakHelper) CreateUser(ctx context.Context, user gocloak.User) (string, error) {
+	var userID string
+	err := s.WithServiceToken(ctx, func(token string) error {
+		var err error
+		userID, err = s.Client.CreateUser(ctx, token, s.Realm, user)
+		return err
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to create user %s: %w", safeDeref(user.Username), err)
+	}
+
+	return userID, nil
+}
+
+// SetPassword sets a user's password, a temporary one must be changed on next login
+func (s *CloakHelper) SetPassword(ctx context.Context, targetUserID, password string, temporary bool) error {
+	err := s.WithServiceToken(ctx, func(token string) error {
+		return s.Client.SetPassword(ctx, token, targetUserID, s.Realm, password, temporary)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set password of user %s: %w", targetUserID, err)
+	}
+
+	return nil
+}
+
+// DeleteUser deletes a user
+func (s *CloakHelper) DeleteUser(ctx context.Context, targetUserID string) error {
+	err := s.WithServiceToken(ctx, func(token string) error {
+		return s.Client.DeleteUser(ctx, token, s.Realm, targetUserID)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete user %s: %w", targetUserID, err)
+	}
+
+	return nil
+}
+
+// LogoutUser ends every session of a user
+func (s *CloakHelper) LogoutUser(ctx context.Context, targetUserID string) error {
+	err := s.WithServiceToken(ctx, func(token string) error {
+		return s.Client.LogoutAllSessions(ctx, token, s.Realm, targetUserID)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to logout user %s: %w", targetUserID, err)
+	}
+
+	return nil
+}
+
+// GetUserSessions lists the active sessions of a user
+func (s *CloakHelper) GetUserSessions(ctx context.Context, targetUserID string) ([]*gocloak.UserSessionRepresentation, error) {
+	var sessions []*gocloak.UserSessionRepresentation
+	err := s.WithServiceToken(ctx, func(token string) error {
+		var err error
+		sessions, err = s.Client.GetUserSessions(ctx, token, s.Realm, targetUserID)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sessions of user %s: %w", targetUserID, err)
+	}
+
+	return sessions, nil
+}
+
+// LogoutSession ends one session
+func (s *CloakHelper) LogoutSession(ctx context.Context, sessionID string) error {
+	err := s.WithServiceToken(ctx, func(token string) error {
+		return s.Client.LogoutUserSession(ctx, token, s.Realm, sessionID)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to logout session %s: %w", sessionID, err)
+	}
+
+	return nil
+}
+
+// AddUserToGroup makes a user a direct member of a group
+func (s *CloakHelper) AddUserToGroup(ctx context.Context, targetUserID, groupID string) error {
+	err := s.WithServiceToken(ctx, func(token string) error {
+		return s.Client.AddUserToGroup(ctx, token, s.Realm, targetUserID, groupID)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add user %s to group %s: %w", targetUserID, groupID, err)
+	}
+
+	return nil
+}
+
+// RemoveUserFromGroup ends a user's direct membership of a group
+func (s *CloakHelper) RemoveUserFromGroup(ctx context.Context, targetUserID, groupID string) error {
+	err := s.WithServiceToken(ctx, func(token string) error {
+		return s.Client.DeleteUserFromGroup(ctx, token, s.Realm, targetUserID, groupID)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to remove user %s from group %s: %w", targetUserID, groupID, err)
+	}
+
+	return nil
+}
+
+// ListGroups fetches one page of top-level groups, search matches group names
+func (s *CloakHelper) ListGroups(ctx context.Context, search string, first, max int) ([]*gocloak.Group, error) {
+	params := gocloak.GetGroupsParams{
+		First: gocloak.IntP(first),
+		Max:   gocloak.IntP(max),
+	}
+	if search != "" {
+		params.Search = gocloak.StringP(search)
+	}
+
+	var groups []*gocloak.Group
+	err := s.WithServiceToken(ctx, func(token string) error {
+		var err error
+		groups, err = s.Client.GetGroups(ctx, token, s.Realm, params)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list groups: %w", err)
+	}
+
+	return groups, nil
+}
+
+// GetUserGroups fetches the groups a user is a direct member of
+func (s *CloakHelper) GetUserGroups(ctx context.Context, targetUserID string) ([]*gocloak.Group, error) {
+	var groups []*gocloak.Group
+	err := s.WithServiceToken(ctx, func(token string) error {
+		var err error
+		groups, err = s.Client.GetUserGroups(ctx, token, s.Realm, targetUserID, gocloak.GetGroupsParams{})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get groups of user %s: %w", targetUserID, err)
+	}
+
+	return groups, nil
+}
+
+// GetEffectiveClientRoles fetches the roles a user has in every client of the realm, including roles
+// granted through groups and composite roles. The result is keyed by client ID, clients without roles are omitted.
+func (s *CloakHelper) GetEffectiveClientRoles(ctx context.Context, targetUserID string) (map[string][]string, error) {
+	var roles map[string][]string
+	err := s.WithServiceToken(ctx, func(token string) error {
+		clients, err := s.Client.GetClients(ctx, token, s.Realm, gocloak.GetClientsParams{})
+		if err != nil {
+			return err
+		}
+
+		var mu sync.Mutex
+		roles = make(map[string][]string)
+		group, groupCtx := errgroup.WithContext(ctx)
+		group.SetLimit(clientRoleLookups)
+		for _, client := range clients {
+			if client.ID == nil || client.ClientID == nil {
+				continue
+			}
+			group.Go(func() error {
+				clientRoles, err := s.Client.GetCompositeClientRolesByUserID(groupCtx, token, s.Realm, *client.ID, targetUserID)
+				if err != nil || len(clientRoles) == 0 {
+					return err
+				}
+				names := make([]string, 0, len(clientRoles))
+				for _, role := range clientRoles {
+					names = append(names, safeDeref(role.Name))
+				}
+				sort.Strings(names)
+
+				mu.Lock()
+				roles[*client.ClientID] = names
+				mu.Unlock()
+				return nil
+			})
+		}
+		return group.Wait()
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client roles of user %s: %w", targetUserID, err)
+	}
+
+	return roles, nil
+}
+
+// ListUsers fetches one page of the users matching params. The total number of matches is -1 when unknown:
+// Keycloak's count endpoint ignores exact, so it's only computed for inexact searches or on the last page.
+func (s *CloakHelper) ListUsers(ctx context.Context, params gocloak.GetUsersParams) ([]*gocloak.User, int, error) {
+	var users []*gocloak.User
+	total := -1
+	err := s.WithServiceToken(ctx, func(token string) error {
+		var err error
+		users, err = s.Client.GetUsers(ctx, token, s.Realm, params)
+		if err != nil || (params.Exact != nil && *params.Exact) {
+			return err
+		}
+
+		countParams := params
+		countParams.First, countParams.Max, countParams.BriefRepresentation = nil, nil, nil
+		total, err = s.Client.GetUserCount(ctx, token, s.Realm, countParams)
+		return err
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	if total < 0 && params.Max != nil && len(users) < *params.Max {
+		total = len(users)
+		if params.First != nil {
+			total += *params.First
+		}
+	}
+	return users, total, nil
+}
+
+// GetGroupByPath resolves a group path like parent/child, the leading slash is optional
+func (s *CloakHelper) GetGroupByPath(ctx context.Context, path string) (*gocloak.Group, error) {
+	var group *gocloak.Group
+	err := s.WithServiceToken(ctx, func(token string) error {
+		var err error
+		group, err = s.Client.GetGroupByPath(ctx, token, s.Realm, strings.TrimPrefix(path, "/"))
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get group %s: %w", path, err)
+	}
+
+	return group, nil
+}
+
+// GetGroupMembers fetches one page of the direct members of a group
+func (s *CloakHelper) GetGroupMembers(ctx context.Context, groupID string, first, max int) ([]*gocloak.User, error) {
+	var users []*gocloak.User
+	err := s.WithServiceToken(ctx, func(token string) error {
+		var err error
+		users, err = s.Client.GetGroupMembers(ctx, token, s.Realm, groupID, gocloak.GetGroupsParams{
+			First: gocloak.IntP(first),
+			Max:   gocloak.IntP(max),
+		})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get members of group %s: %w", groupID, err)
+	}
+
+	return users, nil
+}
+
+// GetRealmCerts fetches the realm's JSON Web Key Set.
+// Unlike Client.GetCerts it always hits Keycloak, so rotated keys are picked up immediately.
+func (s *CloakHelper) GetRealmCerts(ctx context.Context) (*gocloak.CertResponse, error) {
+	var certs gocloak.CertResponse
+	resp, err := s.Client.GetRequest(ctx).
+		SetResult(&certs).
+		Get(s.RealmURL("protocol", "openid-connect", "certs"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get realm certs: %w", err)
+	}
+	if resp.IsError() {
+		return nil, fmt.Errorf("failed to get realm certs: %s", resp.Status())
+	}
+
+	return &certs, nil
+}
+
+// IntrospectToken calls the realm's token introspection endpoint with our client credentials.
+// The result holds the token claims plus "active", which is false for revoked or expired tokens.
+func (s *CloakHelper) IntrospectToken(ctx context.Context, token string) (map[string]interface{}, error) {
+	var result map[string]interface{}
+	resp, err := s.Client.GetRequestWithBasicAuth(ctx, s.ClientID, s.ClientSecret).
+		SetFormData(map[string]string{
+			"token":           token,
+			"token_type_hint": "access_token",
+		}).
+		SetResult(&result).
+		Post(s.RealmURL("protocol", "openid-connect", "token", "introspect"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to introspect token: %w", err)
+	}
+	if resp.IsError() {
+		return nil, fmt.Errorf("failed to introspect token: %s", resp.Status())
+	}
+
+	return result, nil
+}
+
+// PasswordLogin runs the password grant for a user with our client credentials
+func (s *CloakHelper) PasswordLogin(ctx context.Context, username, password string) (*gocloak.JWT, error) {
+	jwt, err := s.Client.Login(ctx, s.ClientID, s.ClientSecret, s.Realm, username, password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to log in user %s: %w", username, err)
+	}
+
+	return jwt, nil
+}
+
+// RefreshUserToken exchanges a refresh token issued to our client for new tokens
+func (s *CloakHelper) RefreshUserToken(ctx context.Context, refreshToken string) (*gocloak.JWT, error) {
+	jwt, err := s.Client.RefreshToken(ctx, refreshToken, s.ClientID, s.ClientSecret, s.Realm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh token: %w", err)
+	}
+
+	return jwt, nil
+}
+
+// ExchangeCode redeems an authorization code for tokens with our client credentials and the PKCE code verifier.
+// gocloak's TokenOptions has no code_verifier, so the request is built here; errors are shaped like gocloak's.
+func (s *CloakHelper) ExchangeCode(ctx context.Context, code, redirectURI, codeVerifier string) (*gocloak.JWT, error) {
+	var jwt gocloak.JWT
+	resp, err := s.Client.GetRequestWithBasicAuth(ctx, s.ClientID, s.ClientSecret).
+		SetFormData(map[string]string{
+			"grant_type":    "authorization_code",
+			"code":          code,
+			"redirect_uri":  redirectURI,
+			"code_verifier": codeVerifier,
+		}).
+		SetResult(&jwt).
+		Post(s.RealmURL("protocol", "openid-connect", "token"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange code: %w", &gocloak.APIError{Message: err.Error()})
+	}
+	if resp.IsError() {
+		message := resp.Status()
+		if e, ok := resp.Error().(*gocloak.HTTPErrorResponse); ok && e.NotEmpty() {
+			message = fmt.Sprintf("%s: %s", resp.Status(), e)
+		}
+		return nil, fmt.Errorf("failed to exchange code: %w", &gocloak.APIError{Code: resp.StatusCode(), Message: message})
+	}
+
+	return &jwt, nil
+}
+
+// RevokeRefreshToken revokes a refresh token issued to our client, which ends its client session.
+// Keycloak accepts unknown and expired tokens, as RFC 7009 requires.
+func (s *CloakHelper) RevokeRefreshToken(ctx context.Context, refreshToken string) error {
+	if err := s.Client.RevokeToken(ctx, s.Realm, s.ClientID, s.ClientSecret, refreshToken); err != nil {
+		return fmt.Errorf("failed to revoke refresh token: %w", err)
+	}
+
+	return nil
+}
+
+// RealmURL builds a URL below the realm's public endpoint, e.g. {url}/realms/{realm}/protocol/openid-connect/certs
+func (s *CloakHelper) RealmURL(path ...string) string {
+	return strings.Join(append([]string{s.URL, "realms", s.Realm}, path...), "/")
 }
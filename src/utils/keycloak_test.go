@@ -0,0 +1,409 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Nerzal/gocloak/v13"
+)
+
+// fakeKeycloak serves the client-credentials token endpoint and the admin users API
+type fakeKeycloak struct {
+	server *httptest.Server
+
+	mu        sync.Mutex
+	logins    int
+	lookups   int
+	expiresIn int
+	token     string
+	// loginDelay slows down the token endpoint
+	loginDelay time.Duration
+	// stalled makes user lookups hang until the client gives up
+	stalled bool
+	// lookupGate holds the answers of user lookups until it is closed
+	lookupGate chan struct{}
+	users      map[string]gocloak.User
+	// roles of each user keyed by client ID, groups of each user
+	roles  map[string]map[string][]string
+	groups map[string][]gocloak.Group
+}
+
+// fakeClients are the clients of the fake realm
+var fakeClients = []string{"account", "omniauth", "omndapi"}
+
+func newFakeKeycloak(t *testing.T) *fakeKeycloak {
+	t.Helper()
+	kc := &fakeKeycloak{
+		expiresIn: 300,
+		users:     make(map[string]gocloak.User),
+		roles:     make(map[string]map[string][]string),
+		groups:    make(map[string][]gocloak.Group),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /realms/"+testRealm+"/protocol/openid-connect/token", func(w http.ResponseWriter, r *http.Request) {
+		kc.mu.Lock()
+		delay := kc.loginDelay
+		kc.mu.Unlock()
+		time.Sleep(delay)
+		kc.mu.Lock()
+		kc.logins++
+		kc.token = fmt.Sprintf("token-%d", kc.logins)
+		body := map[string]interface{}{"access_token": kc.token, "expires_in": kc.expiresIn}
+		kc.mu.Unlock()
+		writeJSON(w, http.StatusOK, body)
+	})
+	mux.HandleFunc("GET /admin/realms/"+testRealm+"/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		kc.mu.Lock()
+		defer kc.mu.Unlock()
+		kc.lookups++
+		if kc.stalled {
+			kc.mu.Unlock()
+			<-r.Context().Done()
+			kc.mu.Lock()
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer "+kc.token {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "HTTP 401 Unauthorized"})
+			return
+		}
+		user, ok := kc.users[r.PathValue("id")]
+		if !ok {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "User not found"})
+			return
+		}
+		// The user is read before the gate, so it is answered as it was when the lookup arrived
+		if gate := kc.lookupGate; gate != nil {
+			kc.mu.Unlock()
+			<-gate
+			kc.mu.Lock()
+		}
+		writeJSON(w, http.StatusOK, user)
+	})
+	mux.HandleFunc("GET /admin/realms/"+testRealm+"/users", func(w http.ResponseWriter, r *http.Request) {
+		users := kc.search(r.URL.Query())
+		first, _ := strconv.Atoi(r.URL.Query().Get("first"))
+		max, _ := strconv.Atoi(r.URL.Query().Get("max"))
+		users = users[min(first, len(users)):]
+		users = users[:min(max, len(users))]
+		writeJSON(w, http.StatusOK, users)
+	})
+	mux.HandleFunc("GET /admin/realms/"+testRealm+"/users/count", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, len(kc.search(r.URL.Query())))
+	})
+	mux.HandleFunc("GET /admin/realms/"+testRealm+"/users/{id}/groups", func(w http.ResponseWriter, r *http.Request) {
+		kc.mu.Lock()
+		defer kc.mu.Unlock()
+		writeJSON(w, http.StatusOK, kc.groups[r.PathValue("id")])
+	})
+	mux.HandleFunc("GET /admin/realms/"+testRealm+"/group-by-path/{path...}", func(w http.ResponseWriter, r *http.Request) {
+		kc.mu.Lock()
+		defer kc.mu.Unlock()
+		for _, groups := range kc.groups {
+			for _, group := range groups {
+				if *group.Path == "/"+r.PathValue("path") {
+					writeJSON(w, http.StatusOK, group)
+					return
+				}
+			}
+		}
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "Group path does not exist"})
+	})
+	mux.HandleFunc("GET /admin/realms/"+testRealm+"/clients", func(w http.ResponseWriter, r *http.Request) {
+		clients := make([]gocloak.Client, 0, len(fakeClients))
+		for _, clientID := range fakeClients {
+			clients = append(clients, gocloak.Client{ID: gocloak.StringP("id-" + clientID), ClientID: gocloak.StringP(clientID)})
+		}
+		writeJSON(w, http.StatusOK, clients)
+	})
+	mux.HandleFunc("GET /admin/realms/"+testRealm+"/users/{id}/role-mappings/clients/{client}/composite", func(w http.ResponseWriter, r *http.Request) {
+		kc.mu.Lock()
+		defer kc.mu.Unlock()
+		roles := []gocloak.Role{}
+		for _, role := range kc.roles[r.PathValue("id")][strings.TrimPrefix(r.PathValue("client"), "id-")] {
+			roles = append(roles, gocloak.Role{Name: gocloak.StringP(role)})
+		}
+		writeJSON(w, http.StatusOK, roles)
+	})
+	kc.server = httptest.NewServer(mux)
+	t.Cleanup(kc.server.Close)
+	return kc
+}
+
+func (kc *fakeKeycloak) addUser(id, username string) {
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+	kc.users[id] = gocloak.User{ID: gocloak.StringP(id), Username: gocloak.StringP(username)}
+}
+
+// search mimics Keycloak's user search on username, sorted by ID
+func (kc *fakeKeycloak) search(query url.Values) []gocloak.User {
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+
+	users := []gocloak.User{}
+	for _, user := range kc.users {
+		switch {
+		case query.Get("search") != "" && !strings.Contains(*user.Username, query.Get("search")):
+		case query.Get("username") != "" && query.Get("exact") == "true" && *user.Username != query.Get("username"):
+		default:
+			users = append(users, user)
+		}
+	}
+	sort.Slice(users, func(i, j int) bool { return *users[i].ID < *users[j].ID })
+	return users
+}
+
+func (kc *fakeKeycloak) grantRoles(userID, clientID string, roles ...string) {
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+	if kc.roles[userID] == nil {
+		kc.roles[userID] = make(map[string][]string)
+	}
+	kc.roles[userID][clientID] = append(kc.roles[userID][clientID], roles...)
+}
+
+// joinGroup adds the user to the group at path, e.g. parent/child
+func (kc *fakeKeycloak) joinGroup(userID, path string) {
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+	kc.groups[userID] = append(kc.groups[userID], gocloak.Group{
+		ID:   gocloak.StringP("id-" + path),
+		Name: gocloak.StringP(path[strings.LastIndex(path, "/")+1:]),
+		Path: gocloak.StringP("/" + path),
+	})
+}
+
+// revokeToken makes Keycloak reject the current service token
+func (kc *fakeKeycloak) revokeToken() {
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+	kc.token = "revoked"
+}
+
+func (kc *fakeKeycloak) counts() (logins, lookups int) {
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+	return kc.logins, kc.lookups
+}
+
+func (kc *fakeKeycloak) helper() *CloakHelper {
+	return &CloakHelper{
+		Client:       gocloak.NewClient(kc.server.URL),
+		URL:          kc.server.URL,
+		Realm:        testRealm,
+		ClientID:     "omniauth",
+		ClientSecret: "secret",
+	}
+}
+
+func writeJSON(w http.ResponseWriter, code int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(body)
+}
+
+func TestServiceTokenIsCached(t *testing.T) {
+	kc := newFakeKeycloak(t)
+	kc.addUser("u1", "alice")
+	helper := kc.helper()
+
+	for i := 0; i < 3; i++ {
+		user, err := helper.GetUserProfile(context.Background(), "u1")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if *user.Username != "alice" {
+			t.Errorf("unexpected user %v", *user.Username)
+		}
+	}
+	if logins, _ := kc.counts(); logins != 1 {
+		t.Errorf("expected a single login, got %d", logins)
+	}
+}
+
+func TestServiceTokenCollapsesConcurrentLogins(t *testing.T) {
+	kc := newFakeKeycloak(t)
+	helper := kc.helper()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := helper.ServiceToken(context.Background()); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if logins, _ := kc.counts(); logins != 1 {
+		t.Errorf("expected concurrent callers to share one login, got %d", logins)
+	}
+}
+
+func TestServiceTokenLoginOutlivesCaller(t *testing.T) {
+	kc := newFakeKeycloak(t)
+	kc.loginDelay = 100 * time.Millisecond
+	helper := kc.helper()
+
+	// The caller starting the shared login gives up, the other waiter still gets the token
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	started := make(chan error, 1)
+	go func() {
+		_, err := helper.ServiceToken(ctx)
+		started <- err
+	}()
+	time.Sleep(5 * time.Millisecond)
+	if _, err := helper.ServiceToken(context.Background()); err != nil {
+		t.Errorf("expected the waiter to get the token, got %v", err)
+	}
+	if err := <-started; !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the first caller to stop at its deadline, got %v", err)
+	}
+	if logins, _ := kc.counts(); logins != 1 {
+		t.Errorf("expected 1 login, got %d", logins)
+	}
+}
+
+func TestServiceTokenRetriesOn401(t *testing.T) {
+	kc := newFakeKeycloak(t)
+	kc.addUser("u1", "alice")
+	helper := kc.helper()
+
+	if _, err := helper.GetUserProfile(context.Background(), "u1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	kc.revokeToken()
+	if _, err := helper.GetUserProfile(context.Background(), "u1"); err != nil {
+		t.Fatalf("expected retry with a fresh token: %v", err)
+	}
+	if logins, lookups := kc.counts(); logins != 2 || lookups != 3 {
+		t.Errorf("expected 2 logins and 3 lookups, got %d and %d", logins, lookups)
+	}
+}
+
+func TestServiceTokenRefreshesBeforeExpiry(t *testing.T) {
+	kc := newFakeKeycloak(t)
+	helper := kc.helper()
+
+	first, err := helper.ServiceToken(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Pretend the token is inside its refresh window: the current token is still served
+	// while a new one is fetched in the background
+	helper.tokenMu.Lock()
+	helper.tokenRefreshAt = time.Now().Add(-time.Second)
+	helper.tokenMu.Unlock()
+	if token, _ := helper.ServiceToken(context.Background()); token != first {
+		t.Errorf("expected the still valid token %s, got %s", first, token)
+	}
+
+	for i := 0; i < 100; i++ {
+		helper.tokenMu.RLock()
+		token := helper.token
+		helper.tokenMu.RUnlock()
+		if token != first && strings.HasPrefix(token, "token-") {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Error("expected the token to be refreshed in the background")
+}
+
+func TestGetEffectiveClientRolesAndGroups(t *testing.T) {
+	kc := newFakeKeycloak(t)
+	kc.addUser("u1", "alice")
+	kc.grantRoles("u1", "omniauth", "user", "admin")
+	kc.grantRoles("u1", "account", "view-profile")
+	kc.joinGroup("u1", "admin-group")
+	helper := kc.helper()
+
+	roles, err := helper.GetEffectiveClientRoles(context.Background(), "u1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(roles) != 2 || strings.Join(roles["omniauth"], ",") != "admin,user" || roles["account"][0] != "view-profile" {
+		t.Errorf("unexpected roles %v", roles)
+	}
+	if _, ok := roles["omndapi"]; ok {
+		t.Error("expected clients without roles to be omitted")
+	}
+
+	groups, err := helper.GetUserGroups(context.Background(), "u1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(groups) != 1 || *groups[0].Path != "/admin-group" {
+		t.Errorf("unexpected groups %v", groups)
+	}
+}
+
+func TestListUsers(t *testing.T) {
+	kc := newFakeKeycloak(t)
+	for i := 0; i < 5; i++ {
+		kc.addUser(fmt.Sprintf("u%d", i), fmt.Sprintf("user%d", i))
+	}
+	helper := kc.helper()
+
+	users, total, err := helper.ListUsers(context.Background(), gocloak.GetUsersParams{
+		Search: gocloak.StringP("user"),
+		First:  gocloak.IntP(2),
+		Max:    gocloak.IntP(2),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(users) != 2 || *users[0].ID != "u2" || total != 5 {
+		t.Errorf("unexpected page %d users, total %d", len(users), total)
+	}
+
+	// The count endpoint ignores exact, so the total is only known on the last page
+	users, total, err = helper.ListUsers(context.Background(), gocloak.GetUsersParams{
+		Username: gocloak.StringP("user3"),
+		Exact:    gocloak.BoolP(true),
+		Max:      gocloak.IntP(10),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(users) != 1 || total != 1 {
+		t.Errorf("unexpected page %d users, total %d", len(users), total)
+	}
+}
+
+func TestGetGroupByPath(t *testing.T) {
+	kc := newFakeKeycloak(t)
+	kc.addUser("u1", "alice")
+	kc.joinGroup("u1", "tiers/pro-group")
+	helper := kc.helper()
+
+	for _, path := range []string{"tiers/pro-group", "/tiers/pro-group"} {
+		group, err := helper.GetGroupByPath(context.Background(), path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if *group.Name != "pro-group" {
+			t.Errorf("unexpected group %v", *group.Name)
+		}
+	}
+
+	if _, err := helper.GetGroupByPath(context.Background(), "missing"); !IsNotFound(err) {
+		t.Errorf("expected not found, got %v", err)
+	}
+}
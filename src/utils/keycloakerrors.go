@@ -0,0 +1,170 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/Nerzal/gocloak/v13"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Reasons attached to errors translated from Keycloak responses
+const (
+	ReasonNotFound                = "NOT_FOUND"
+	ReasonInvalidRequest          = "INVALID_REQUEST"
+	ReasonAlreadyExists           = "ALREADY_EXISTS"
+	ReasonIdentityProviderDenied  = "IDENTITY_PROVIDER_DENIED"
+	ReasonServiceAccountRejected  = "SERVICE_ACCOUNT_REJECTED"
+	ReasonIdentityProviderTimeout = "IDENTITY_PROVIDER_TIMEOUT"
+	ReasonIdentityProviderError   = "IDENTITY_PROVIDER_ERROR"
+)
+
+// Reasons attached to errors of the token endpoint
+const (
+	ReasonInvalidCredentials   = "INVALID_CREDENTIALS"
+	ReasonAccountDisabled      = "ACCOUNT_DISABLED"
+	ReasonAccountSetupRequired = "ACCOUNT_SETUP_REQUIRED"
+	ReasonInvalidGrant         = "INVALID_GRANT"
+	ReasonClientRejected       = "CLIENT_REJECTED"
+)
+
+// ErrServiceCredentials marks errors caused by Keycloak rejecting our own service account,
+// as opposed to the caller's credentials
+var ErrServiceCredentials = errors.New("Keycloak rejected the service account")
+
+// KeycloakError translates an error returned by Keycloak into a gRPC status error carrying a
+// google.rpc.ErrorInfo detail. Errors that already are gRPC status errors are returned unchanged.
+func KeycloakError(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	logger := GetLogger(ctx)
+	switch {
+	case errors.Is(err, ErrServiceCredentials):
+		logger.WithError(err).Error("Keycloak rejected the service account")
+		return StatusWithReason(codes.Unavailable, ReasonServiceAccountRejected, "identity provider rejected the service credentials")
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, "request canceled")
+	case isTimeout(err):
+		logger.WithError(err).Warn("Keycloak request timed out")
+		return StatusWithReason(codes.DeadlineExceeded, ReasonIdentityProviderTimeout, "identity provider timed out")
+	case isConnectionError(err):
+		logger.WithError(err).Error("Keycloak is unreachable")
+		return StatusWithReason(codes.Unavailable, ReasonIdentityProviderUnavailable, "identity provider is unavailable")
+	}
+
+	var apiErr *gocloak.APIError
+	if !errors.As(err, &apiErr) {
+		logger.WithError(err).Error("unexpected Keycloak error")
+		return StatusWithReason(codes.Internal, ReasonIdentityProviderError, "identity provider error")
+	}
+
+	metadata := map[string]string{"http_status": strconv.Itoa(apiErr.Code)}
+	switch {
+	case apiErr.Code == http.StatusNotFound:
+		return StatusWithMetadata(codes.NotFound, ReasonNotFound, apiErr.Message, metadata)
+	case apiErr.Code == http.StatusBadRequest:
+		return StatusWithMetadata(codes.InvalidArgument, ReasonInvalidRequest, apiErr.Message, metadata)
+	case apiErr.Code == http.StatusConflict:
+		return StatusWithMetadata(codes.AlreadyExists, ReasonAlreadyExists, apiErr.Message, metadata)
+	case apiErr.Code == http.StatusUnauthorized || apiErr.Code == http.StatusForbidden:
+		return StatusWithMetadata(codes.PermissionDenied, ReasonIdentityProviderDenied, apiErr.Message, metadata)
+	case apiErr.Code >= http.StatusInternalServerError:
+		logger.WithError(err).Error("Keycloak failed")
+		return StatusWithMetadata(codes.Unavailable, ReasonIdentityProviderUnavailable, "identity provider is unavailable", metadata)
+	default:
+		logger.WithError(err).Error("unexpected Keycloak error")
+		return StatusWithMetadata(codes.Internal, ReasonIdentityProviderError, "identity provider error", metadata)
+	}
+}
+
+// TokenError translates an error of Keycloak's token or revocation endpoint.
+// OAuth errors (RFC 6749 section 5.2) are told apart by their error code and description,
+// anything else is translated by KeycloakError.
+func TokenError(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	code, description := oauthError(err)
+	switch code {
+	case "invalid_grant":
+		switch description {
+		case "Invalid user credentials":
+			return StatusWithReason(codes.Unauthenticated, ReasonInvalidCredentials, "invalid username or password")
+		case "Account disabled":
+			return StatusWithReason(codes.PermissionDenied, ReasonAccountDisabled, "account is disabled")
+		case "Account is not fully set up":
+			return StatusWithReason(codes.FailedPrecondition, ReasonAccountSetupRequired, "account has pending required actions, sign in through the browser to complete them")
+		default:
+			// Expired, revoked or foreign refresh tokens and ended sessions
+			return StatusWithReason(codes.Unauthenticated, ReasonInvalidGrant, "token is invalid or expired")
+		}
+	case "invalid_client", "unauthorized_client":
+		GetLogger(ctx).WithError(err).Error("Keycloak rejected the omniauth client")
+		return StatusWithReason(codes.Unavailable, ReasonClientRejected, "identity provider rejected the client")
+	}
+	return KeycloakError(ctx, err)
+}
+
+// oauthError extracts the OAuth error code and description gocloak puts into the APIError message,
+// e.g. "400 Bad Request: invalid_grant: Invalid user credentials"
+func oauthError(err error) (code, description string) {
+	var apiErr *gocloak.APIError
+	if !errors.As(err, &apiErr) || apiErr.Code < http.StatusBadRequest {
+		return "", ""
+	}
+	_, rest, ok := strings.Cut(apiErr.Message, ": ")
+	if !ok {
+		return "", ""
+	}
+	code, description, _ = strings.Cut(rest, ": ")
+	return code, description
+}
+
+// gocloak flattens transport errors into an APIError with code 0 and the error text as message,
+// so besides the error chain the message has to be inspected as well
+func isTimeout(err error) bool {
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return true
+	}
+	return isTransportError(err, "context deadline exceeded", "Client.Timeout exceeded", "i/o timeout")
+}
+
+func isConnectionError(err error) bool {
+	var opErr *net.OpError
+	var dnsErr *net.DNSError
+	if errors.Is(err, syscall.ECONNREFUSED) || errors.As(err, &opErr) || errors.As(err, &dnsErr) {
+		return true
+	}
+	return isTransportError(err, "connection refused", "connection reset", "no such host", "EOF")
+}
+
+func isTransportError(err error, fragments ...string) bool {
+	var apiErr *gocloak.APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != 0 {
+		return false
+	}
+	for _, fragment := range fragments {
+		if strings.Contains(apiErr.Message, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+// serviceCredentialsError marks err with ErrServiceCredentials
+func serviceCredentialsError(err error) error {
+	return fmt.Errorf("%w: %w", ErrServiceCredentials, err)
+}
@@ -0,0 +1,105 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Nerzal/gocloak/v13"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestKeycloakErrorMapping(t *testing.T) {
+	apiError := func(code int, message string) error {
+		return fmt.Errorf("failed to get user u1: %w", &gocloak.APIError{Code: code, Message: message})
+	}
+
+	tests := []struct {
+		name   string
+		err    error
+		code   codes.Code
+		reason string
+	}{
+		{"not found", apiError(http.StatusNotFound, "404 Not Found: User not found"), codes.NotFound, ReasonNotFound},
+		{"bad request", apiError(http.StatusBadRequest, "400 Bad Request"), codes.InvalidArgument, ReasonInvalidRequest},
+		{"conflict", apiError(http.StatusConflict, "409 Conflict"), codes.AlreadyExists, ReasonAlreadyExists},
+		{"caller denied", apiError(http.StatusForbidden, "403 Forbidden"), codes.PermissionDenied, ReasonIdentityProviderDenied},
+		{"service account denied", serviceCredentialsError(apiError(http.StatusForbidden, "403 Forbidden")), codes.Unavailable, ReasonServiceAccountRejected},
+		{"server error", apiError(http.StatusBadGateway, "502 Bad Gateway"), codes.Unavailable, ReasonIdentityProviderUnavailable},
+		{"timeout", apiError(0, "could not get user: Get \"http://kc\": context deadline exceeded"), codes.DeadlineExceeded, ReasonIdentityProviderTimeout},
+		{"connection refused", apiError(0, "could not get user: dial tcp 127.0.0.1:8080: connect: connection refused"), codes.Unavailable, ReasonIdentityProviderUnavailable},
+		{"unknown", errors.New("boom"), codes.Internal, ReasonIdentityProviderError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := KeycloakError(context.Background(), tt.err)
+			if status.Code(err) != tt.code || errorReason(t, err) != tt.reason {
+				t.Errorf("expected %v %s, got %v", tt.code, tt.reason, err)
+			}
+		})
+	}
+
+	// Status errors pass through
+	original := authError(ReasonTokenExpired, "token expired")
+	if err := KeycloakError(context.Background(), original); err != original {
+		t.Errorf("expected status error to be returned unchanged, got %v", err)
+	}
+}
+
+func TestTokenErrorMapping(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		code        string
+		description string
+		want        codes.Code
+		reason      string
+	}{
+		{"bad password", http.StatusUnauthorized, "invalid_grant", "Invalid user credentials", codes.Unauthenticated, ReasonInvalidCredentials},
+		{"disabled", http.StatusBadRequest, "invalid_grant", "Account disabled", codes.PermissionDenied, ReasonAccountDisabled},
+		{"required actions", http.StatusBadRequest, "invalid_grant", "Account is not fully set up", codes.FailedPrecondition, ReasonAccountSetupRequired},
+		{"expired refresh token", http.StatusBadRequest, "invalid_grant", "Token is not active", codes.Unauthenticated, ReasonInvalidGrant},
+		{"bad client secret", http.StatusUnauthorized, "unauthorized_client", "Invalid client or Invalid client credentials", codes.Unavailable, ReasonClientRejected},
+		{"other error", http.StatusBadRequest, "invalid_request", "Missing parameter: username", codes.InvalidArgument, ReasonInvalidRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				writeJSON(w, tt.status, map[string]string{"error": tt.code, "error_description": tt.description})
+			}))
+			defer server.Close()
+			helper := &CloakHelper{Client: gocloak.NewClient(server.URL), URL: server.URL, Realm: testRealm}
+
+			_, err := helper.PasswordLogin(context.Background(), "alice", "secret")
+			err = TokenError(context.Background(), err)
+			if status.Code(err) != tt.want || errorReason(t, err) != tt.reason {
+				t.Errorf("expected %v %s, got %v", tt.want, tt.reason, err)
+			}
+		})
+	}
+}
+
+func TestKeycloakErrorFromUnreachableServer(t *testing.T) {
+	kc := newFakeKeycloak(t)
+	helper := kc.helper()
+	kc.server.Close()
+
+	_, err := helper.GetUserProfile(context.Background(), "u1")
+	err = KeycloakError(context.Background(), err)
+	if status.Code(err) != codes.Unavailable || errorReason(t, err) != ReasonIdentityProviderUnavailable {
+		t.Errorf("expected unavailable, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+	_, err = kc.helper().GetUserProfile(ctx, "u1")
+	if err = KeycloakError(ctx, err); status.Code(err) != codes.DeadlineExceeded {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+}
@@ -3,6 +3,7 @@ package utils
 import (
 	"context"
 
+	"github.com/gin-gonic/gin"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
 
@@ -17,7 +18,33 @@ func LoggingInterceptor(
 	info *grpc.UnaryServerInfo,
 	handler grpc.UnaryHandler,
 ) (interface{}, error) {
+	ctx, requestLogger := withRequestLogger(ctx, info.FullMethod)
 
+	// Call the original handler with the new context
+	resp, err := handler(ctx, req)
+
+	logRequestEnd(requestLogger, err)
+	return resp, err
+}
+
+// LoggingStreamInterceptor is a gRPC stream interceptor for logging.
+func LoggingStreamInterceptor(
+	srv interface{},
+	stream grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	ctx, requestLogger := withRequestLogger(stream.Context(), info.FullMethod)
+
+	// Call the original handler with a stream carrying the new context
+	err := handler(srv, WrapServerStream(ctx, stream))
+
+	logRequestEnd(requestLogger, err)
+	return err
+}
+
+// withRequestLogger attaches a logger tagged with the request ID to the context
+func withRequestLogger(ctx context.Context, method string) (context.Context, *logrus.Entry) {
 	// 1. Get or Generate Request ID
 	var requestID string
 
@@ -43,18 +70,51 @@ func LoggingInterceptor(
 
 	// Add a log entry for the start of the request
 	requestLogger.WithFields(logrus.Fields{
-		"method": info.FullMethod,
+		"method": method,
 	}).Debug("gRPC request started")
 
-	// 4. Call the original handler with the new context
-	resp, err := handler(ctx, req)
+	return ctx, requestLogger
+}
 
-	// 5. Log the end of the request
+// logRequestEnd logs the end of the request
+func logRequestEnd(requestLogger *logrus.Entry, err error) {
 	if err != nil {
 		requestLogger.WithError(err).Error("gRPC request finished with error")
 	} else {
 		requestLogger.Debug("gRPC request finished successfully")
 	}
+}
 
-	return resp, err
+// RequestIDMiddleware tags Gin requests with the X-Request-Id header, generating one if missing.
+// The ID is echoed in the response and forwarded to the gRPC layer through the gateway,
+// so gateway and gRPC logs of a request share it.
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader("X-Request-Id")
+		if requestID == "" {
+			requestID = uuid.New().String()
+		}
+		c.Header("X-Request-Id", requestID)
+		// The gateway turns Grpc-Metadata-* headers into metadata
+		c.Request.Header.Set("Grpc-Metadata-X-Request-Id", requestID)
+
+		ctx := WithLogger(c.Request.Context(), logrus.WithField("request_id", requestID))
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// wrappedStream overrides the context of a grpc.ServerStream
+type wrappedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (w *wrappedStream) Context() context.Context {
+	return w.ctx
+}
+
+// WrapServerStream returns a stream whose Context() is ctx, so interceptors can enrich streaming calls
+func WrapServerStream(ctx context.Context, stream grpc.ServerStream) grpc.ServerStream {
+	return &wrappedStream{ServerStream: stream, ctx: ctx}
 }
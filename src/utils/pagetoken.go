@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// ErrInvalidPageToken is returned for page tokens that are malformed or belong to other filters
+var ErrInvalidPageToken = errors.New("invalid page token")
+
+// pageToken is the content of the opaque page tokens, Keycloak's first/max plus a fingerprint
+// of the filters so a token can't be replayed against a different query
+type pageToken struct {
+	First  int    `json:"f"`
+	Max    int    `json:"m"`
+	Filter string `json:"h"`
+}
+
+// EncodePageToken builds the token of the page starting at first
+func EncodePageToken(first, max int, filters ...string) string {
+	data, _ := json.Marshal(pageToken{First: first, Max: max, Filter: filterHash(filters)})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodePageToken returns first/max of a token built by EncodePageToken with the same filters
+func DecodePageToken(token string, filters ...string) (first, max int, err error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, 0, ErrInvalidPageToken
+	}
+	var decoded pageToken
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded.First < 0 || decoded.Max <= 0 {
+		return 0, 0, ErrInvalidPageToken
+	}
+	if decoded.Filter != filterHash(filters) {
+		return 0, 0, ErrInvalidPageToken
+	}
+	return decoded.First, decoded.Max, nil
+}
+
+func filterHash(filters []string) string {
+	sum := sha256.Sum256([]byte(strings.Join(filters, "\x00")))
+	return hex.EncodeToString(sum[:8])
+}
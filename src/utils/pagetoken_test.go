@@ -0,0 +1,19 @@
+package utils
+
+import "testing"
+
+func TestPageToken(t *testing.T) {
+	token := EncodePageToken(40, 20, "alice", "true")
+
+	first, max, err := DecodePageToken(token, "alice", "true")
+	if err != nil || first != 40 || max != 20 {
+		t.Errorf("unexpected page %d/%d: %v", first, max, err)
+	}
+
+	if _, _, err := DecodePageToken(token, "bob", "true"); err != ErrInvalidPageToken {
+		t.Errorf("expected token of other filters to be rejected, got %v", err)
+	}
+	if _, _, err := DecodePageToken("not a token"); err != ErrInvalidPageToken {
+		t.Errorf("expected malformed token to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,92 @@
+package utils
+
+import (
+	"strings"
+
+	"github.com/Nerzal/gocloak/v13"
+
+	"github.com/omnsight/omnauth/gen/oauth/v1"
+)
+
+// AdminRole is the omniauth client role allowed to see and manage every user
+const AdminRole = "admin"
+
+// ProfileVisibilityAttribute is the Keycloak user attribute holding a user's profile visibility:
+// "public" shows the full profile to every caller, "private" hides the display name as well.
+// Without the attribute other callers see the id, username and display name.
+const ProfileVisibilityAttribute = "profileVisibility"
+
+// Profile visibility attribute values
+const (
+	VisibilityPublic  = "public"
+	VisibilityPrivate = "private"
+)
+
+// ProfileLevel is how much of a profile a caller may see
+type ProfileLevel int
+
+const (
+	// ProfileMinimal is the id and username
+	ProfileMinimal ProfileLevel = iota
+	// ProfileBasic adds the display name
+	ProfileBasic
+	// ProfileFull is every field, including the email
+	ProfileFull
+)
+
+// ProfilePolicy decides which profile fields a caller may see.
+// Every RPC returning other users' profiles must go through it.
+type ProfilePolicy struct {
+	AdminRole string
+}
+
+func NewProfilePolicy() *ProfilePolicy {
+	return &ProfilePolicy{AdminRole: AdminRole}
+}
+
+// Level returns how much of target's profile caller may see, caller is nil for anonymous calls
+func (p *ProfilePolicy) Level(caller *Identity, target *gocloak.User) ProfileLevel {
+	if caller != nil && (caller.UserID == safeDeref(target.ID) || caller.HasClientRole(p.AdminRole)) {
+		return ProfileFull
+	}
+
+	switch visibility := strings.ToLower(UserAttribute(target, ProfileVisibilityAttribute)); visibility {
+	case "":
+		return ProfileBasic
+	case VisibilityPublic:
+		return ProfileFull
+	default:
+		// Private and unknown values
+		return ProfileMinimal
+	}
+}
+
+// PublicUser converts target to its API representation, leaving the fields caller may not see empty
+func (p *ProfilePolicy) PublicUser(caller *Identity, target *gocloak.User) *oauth.PublicUser {
+	user := &oauth.PublicUser{
+		Id:       safeDeref(target.ID),
+		Username: safeDeref(target.Username),
+	}
+
+	level := p.Level(caller, target)
+	if level >= ProfileBasic {
+		user.Firstname = safeDeref(target.FirstName)
+		user.Lastname = safeDeref(target.LastName)
+	}
+	if level >= ProfileFull {
+		user.Email = safeDeref(target.Email)
+	}
+	return user
+}
+
+// UserAttribute returns the first value of a user attribute
+func UserAttribute(user *gocloak.User, name string) string {
+	if user.Attributes == nil {
+		return ""
+	}
+	values := (*user.Attributes)[name]
+	if len(values) == 0 {
+		return ""
+	}
+	return strings.TrimSpace(values[0])
+}
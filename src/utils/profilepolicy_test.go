@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/Nerzal/gocloak/v13"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func profileUser(visibility string) *gocloak.User {
+	user := &gocloak.User{
+		ID:        gocloak.StringP("u1"),
+		Username:  gocloak.StringP("alice"),
+		FirstName: gocloak.StringP("Alice"),
+		LastName:  gocloak.StringP("Liddell"),
+		Email:     gocloak.StringP("alice@example.com"),
+	}
+	if visibility != "" {
+		user.Attributes = &map[string][]string{ProfileVisibilityAttribute: {visibility}}
+	}
+	return user
+}
+
+func callerIdentity(userID string, roles ...interface{}) *Identity {
+	return NewIdentity(jwt.MapClaims{
+		"sub":             userID,
+		"resource_access": map[string]interface{}{"omniauth": map[string]interface{}{"roles": roles}},
+	}, "omniauth")
+}
+
+func TestProfilePolicy(t *testing.T) {
+	policy := NewProfilePolicy()
+	self := callerIdentity("u1", "user")
+	other := callerIdentity("u2", "user")
+	admin := callerIdentity("u3", "user", "admin")
+
+	tests := []struct {
+		name       string
+		caller     *Identity
+		visibility string
+		level      ProfileLevel
+	}{
+		{"self", self, VisibilityPrivate, ProfileFull},
+		{"admin", admin, VisibilityPrivate, ProfileFull},
+		{"other", other, "", ProfileBasic},
+		{"other public", other, VisibilityPublic, ProfileFull},
+		{"other private", other, VisibilityPrivate, ProfileMinimal},
+		{"other unknown visibility", other, "friends", ProfileMinimal},
+		{"anonymous", nil, "", ProfileBasic},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if level := policy.Level(tt.caller, profileUser(tt.visibility)); level != tt.level {
+				t.Errorf("expected level %d, got %d", tt.level, level)
+			}
+		})
+	}
+}
+
+func TestProfilePolicyFiltersFields(t *testing.T) {
+	policy := NewProfilePolicy()
+
+	user := policy.PublicUser(callerIdentity("u2", "user"), profileUser(""))
+	if user.Id != "u1" || user.Username != "alice" || user.Firstname != "Alice" || user.Email != "" {
+		t.Errorf("expected email to be hidden, got %v", user)
+	}
+
+	user = policy.PublicUser(callerIdentity("u2", "user"), profileUser(VisibilityPrivate))
+	if user.Username != "alice" || user.Firstname != "" || user.Lastname != "" {
+		t.Errorf("expected display name to be hidden, got %v", user)
+	}
+
+	user = policy.PublicUser(callerIdentity("u1", "user"), profileUser(VisibilityPrivate))
+	if user.Email != "alice@example.com" {
+		t.Errorf("expected full profile for the user themselves, got %v", user)
+	}
+}
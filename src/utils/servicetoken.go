@@ -0,0 +1,134 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Nerzal/gocloak/v13"
+)
+
+const (
+	// serviceTokenRefreshMargin is how long before expiry the service token is refreshed in the background
+	serviceTokenRefreshMargin = 30 * time.Second
+	// serviceTokenRefreshTimeout bounds logins, which run detached from the requests waiting on them
+	serviceTokenRefreshTimeout = 10 * time.Second
+)
+
+// ServiceToken returns the access token of our service account (client credentials grant).
+// The token is cached and refreshed before it expires, concurrent refreshes are collapsed into one login.
+func (s *CloakHelper) ServiceToken(ctx context.Context) (string, error) {
+	s.tokenMu.RLock()
+	token, refreshAt, expiresAt := s.token, s.tokenRefreshAt, s.tokenExpiresAt
+	s.tokenMu.RUnlock()
+
+	now := time.Now()
+	if token != "" && now.Before(expiresAt) {
+		if now.After(refreshAt) {
+			// Still valid, refresh proactively without making this request wait
+			s.tokenGroup.DoChan("token", func() (interface{}, error) {
+				ctx, cancel := context.WithTimeout(context.Background(), serviceTokenRefreshTimeout)
+				defer cancel()
+				return s.loginServiceAccount(ctx)
+			})
+		}
+		return token, nil
+	}
+
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	// One caller giving up must not fail the others waiting on this login,
+	// so the login is detached and each caller only stops waiting for it
+	login := s.tokenGroup.DoChan("token", func() (interface{}, error) {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), serviceTokenRefreshTimeout)
+		defer cancel()
+		return s.loginServiceAccount(ctx)
+	})
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case result := <-login:
+		if result.Err != nil {
+			return "", result.Err
+		}
+		return result.Val.(string), nil
+	}
+}
+
+// InvalidateServiceToken drops the cached service token, e.g. after Keycloak rejected it
+func (s *CloakHelper) InvalidateServiceToken() {
+	s.tokenMu.Lock()
+	s.token = ""
+	s.tokenMu.Unlock()
+}
+
+// WithServiceToken calls fn with the service token. If Keycloak answers 401 the token
+// was revoked or the realm keys rotated, so fn is retried once with a fresh token.
+// Errors caused by Keycloak rejecting the service account are marked with ErrServiceCredentials.
+func (s *CloakHelper) WithServiceToken(ctx context.Context, fn func(token string) error) error {
+	token, err := s.ServiceToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = fn(token)
+	if isUnauthorized(err) {
+		GetLogger(ctx).Warn("service token rejected by Keycloak, logging in again")
+		s.InvalidateServiceToken()
+		if token, err = s.ServiceToken(ctx); err != nil {
+			return err
+		}
+		err = fn(token)
+	}
+
+	if isUnauthorized(err) || isForbidden(err) {
+		return serviceCredentialsError(err)
+	}
+	return err
+}
+
+func (s *CloakHelper) loginServiceAccount(ctx context.Context) (string, error) {
+	jwt, err := s.Client.LoginClient(ctx, s.ClientID, s.ClientSecret, s.Realm)
+	if err != nil {
+		var apiErr *gocloak.APIError
+		if errors.As(err, &apiErr) && apiErr.Code >= http.StatusBadRequest && apiErr.Code < http.StatusInternalServerError {
+			// invalid_client or unauthorized_client: our credentials are wrong
+			err = serviceCredentialsError(err)
+		}
+		return "", fmt.Errorf("failed to login as service account: %w", err)
+	}
+
+	lifetime := time.Duration(jwt.ExpiresIn) * time.Second
+	margin := serviceTokenRefreshMargin
+	if margin > lifetime/2 {
+		margin = lifetime / 2
+	}
+
+	now := time.Now()
+	s.tokenMu.Lock()
+	s.token = jwt.AccessToken
+	s.tokenExpiresAt = now.Add(lifetime)
+	s.tokenRefreshAt = now.Add(lifetime - margin)
+	s.tokenMu.Unlock()
+
+	return jwt.AccessToken, nil
+}
+
+func isUnauthorized(err error) bool {
+	var apiErr *gocloak.APIError
+	return errors.As(err, &apiErr) && apiErr.Code == http.StatusUnauthorized
+}
+
+func isForbidden(err error) bool {
+	var apiErr *gocloak.APIError
+	return errors.As(err, &apiErr) && apiErr.Code == http.StatusForbidden
+}
+
+// IsNotFound reports whether Keycloak answered 404
+func IsNotFound(err error) bool {
+	var apiErr *gocloak.APIError
+	return errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound
+}
@@ -0,0 +1,144 @@
+package utils
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrSessionNotFound is returned for unknown and expired sessions
+var ErrSessionNotFound = errors.New("session not found")
+
+// Session holds the tokens of a browser session. They never leave the server,
+// the browser only gets the encrypted session ID.
+type Session struct {
+	ID           string
+	UserID       string
+	AccessToken  string
+	RefreshToken string
+	IDToken      string
+	// AccessExpiresAt is when the access token has to be refreshed
+	AccessExpiresAt time.Time
+	// ExpiresAt is when the refresh token, and with it the session, expires
+	ExpiresAt time.Time
+}
+
+// SessionStore keeps browser sessions. Implementations must be safe for concurrent use.
+type SessionStore interface {
+	// Get returns the session or ErrSessionNotFound
+	Get(ctx context.Context, id string) (*Session, error)
+	// Save creates or replaces a session, it is dropped once ExpiresAt passes
+	Save(ctx context.Context, session *Session) error
+	Delete(ctx context.Context, id string) error
+}
+
+// sessionSweepInterval is how often MemorySessionStore drops expired sessions
+const sessionSweepInterval = time.Minute
+
+// MemorySessionStore is a SessionStore for a single replica, sessions are lost on restart
+type MemorySessionStore struct {
+	mu        sync.Mutex
+	sessions  map[string]Session
+	lastSweep time.Time
+}
+
+func NewMemorySessionStore() *MemorySessionStore {
+	return &MemorySessionStore{sessions: make(map[string]Session), lastSweep: time.Now()}
+}
+
+func (m *MemorySessionStore) Get(ctx context.Context, id string) (*Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	session, ok := m.sessions[id]
+	if !ok || !time.Now().Before(session.ExpiresAt) {
+		return nil, ErrSessionNotFound
+	}
+	return &session, nil
+}
+
+func (m *MemorySessionStore) Save(ctx context.Context, session *Session) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(m.lastSweep) > sessionSweepInterval {
+		for id, s := range m.sessions {
+			if !now.Before(s.ExpiresAt) {
+				delete(m.sessions, id)
+			}
+		}
+		m.lastSweep = now
+	}
+	m.sessions[session.ID] = *session
+	return nil
+}
+
+func (m *MemorySessionStore) Delete(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.sessions, id)
+	return nil
+}
+
+// Len returns the number of stored sessions, including expired ones not swept yet
+func (m *MemorySessionStore) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.sessions)
+}
+
+// RandomToken returns n random bytes encoded as unpadded base64url
+func RandomToken(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("failed to read random bytes: %v", err))
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// CookieCipher encrypts cookie values with AES-GCM. The cookie name is authenticated too,
+// so a value can't be moved to another cookie.
+type CookieCipher struct {
+	aead cipher.AEAD
+}
+
+// NewCookieCipher takes a 16, 24 or 32 byte key
+func NewCookieCipher(key []byte) (*CookieCipher, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cookie key: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cookie key: %w", err)
+	}
+	return &CookieCipher{aead: aead}, nil
+}
+
+func (c *CookieCipher) Seal(name string, value []byte) string {
+	nonce := make([]byte, c.aead.NonceSize(), c.aead.NonceSize()+len(value)+c.aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		panic(fmt.Sprintf("failed to read random bytes: %v", err))
+	}
+	return base64.RawURLEncoding.EncodeToString(c.aead.Seal(nonce, nonce, value, []byte(name)))
+}
+
+func (c *CookieCipher) Open(name, sealed string) ([]byte, error) {
+	data, err := base64.RawURLEncoding.DecodeString(sealed)
+	if err != nil || len(data) < c.aead.NonceSize() {
+		return nil, errors.New("malformed cookie")
+	}
+	nonce, ciphertext := data[:c.aead.NonceSize()], data[c.aead.NonceSize():]
+	value, err := c.aead.Open(nil, nonce, ciphertext, []byte(name))
+	if err != nil {
+		return nil, errors.New("cookie failed authentication")
+	}
+	return value, nil
+}
@@ -0,0 +1,121 @@
+package utils
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// watchDesc is a server-streaming method that echoes the caller back twice
+var watchDesc = grpc.StreamDesc{
+	StreamName:    "Watch",
+	ServerStreams: true,
+	Handler: func(srv interface{}, stream grpc.ServerStream) error {
+		if err := stream.RecvMsg(&emptypb.Empty{}); err != nil {
+			return err
+		}
+		identity, err := GetIdentity(stream.Context())
+		if err != nil {
+			return err
+		}
+		if _, ok := stream.Context().Value(loggerKey).(*logrus.Entry); !ok {
+			return status.Error(codes.Internal, "missing request logger")
+		}
+		for i := 0; i < 2; i++ {
+			if err := stream.SendMsg(wrapperspb.String(identity.UserID)); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+// startStreamServer serves watchDesc behind the logging and identity stream interceptors
+func startStreamServer(t *testing.T) *grpc.ClientConn {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(grpc.ChainStreamInterceptor(
+		LoggingStreamInterceptor,
+		GrpcGatewayIdentityStreamInterceptor(&Authenticator{
+			ClientID: "omniauth",
+			Verifier: TrustedGatewayVerifier{},
+		}),
+	))
+	server.RegisterService(&grpc.ServiceDesc{
+		ServiceName: "test.Watcher",
+		HandlerType: (*interface{})(nil),
+		Streams:     []grpc.StreamDesc{watchDesc},
+	}, struct{}{})
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func watch(ctx context.Context, conn *grpc.ClientConn) ([]string, error) {
+	stream, err := conn.NewStream(ctx, &watchDesc, "/test.Watcher/Watch")
+	if err != nil {
+		return nil, err
+	}
+	if err := stream.SendMsg(&emptypb.Empty{}); err != nil {
+		return nil, err
+	}
+	if err := stream.CloseSend(); err != nil {
+		return nil, err
+	}
+
+	var received []string
+	for {
+		msg := &wrapperspb.StringValue{}
+		if err := stream.RecvMsg(msg); err != nil {
+			if err == io.EOF {
+				return received, nil
+			}
+			return received, err
+		}
+		received = append(received, msg.Value)
+	}
+}
+
+func TestStreamInterceptorsAuthenticate(t *testing.T) {
+	conn := startStreamServer(t)
+
+	token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "user-1"}).SignedString([]byte("secret"))
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+	received, err := watch(ctx, conn)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(received) != 2 || received[0] != "user-1" {
+		t.Errorf("unexpected messages %v", received)
+	}
+}
+
+func TestStreamInterceptorsRejectAnonymous(t *testing.T) {
+	conn := startStreamServer(t)
+
+	_, err := watch(context.Background(), conn)
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected Unauthenticated, got %v", err)
+	}
+}
@@ -0,0 +1,83 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Tier environment variable keys
+const (
+	// UserTierGroups maps subscription tiers to groups, e.g. "default=default-group,pro=pro-group"
+	UserTierGroups = "USER_TIER_GROUPS"
+	// UserDefaultTier is the tier users fall back to when theirs expires
+	UserDefaultTier = "USER_DEFAULT_TIER"
+)
+
+const (
+	defaultUserTierGroups = "default=default-group,pro=pro-group"
+	defaultUserTier       = "default"
+)
+
+// TierExpiresAtAttribute is the user attribute holding when the current tier expires (RFC 3339)
+const TierExpiresAtAttribute = "tierExpiresAt"
+
+// TierConfig maps each subscription tier to the group granting it. A user is in at most one tier group.
+type TierConfig struct {
+	Groups map[string]string
+	// Default is the tier expired tiers are demoted to
+	Default string
+}
+
+func NewTierConfig() (*TierConfig, error) {
+	value := os.Getenv(UserTierGroups)
+	if value == "" {
+		value = defaultUserTierGroups
+	}
+	config, err := ParseTierConfig(value)
+	if err != nil {
+		return nil, err
+	}
+
+	config.Default = defaultUserTier
+	if tier := os.Getenv(UserDefaultTier); tier != "" {
+		config.Default = tier
+	}
+	if _, ok := config.Groups[config.Default]; !ok {
+		return nil, fmt.Errorf("default tier %q is not one of %v", config.Default, config.Tiers())
+	}
+	return config, nil
+}
+
+// ParseTierConfig parses a comma separated list of tier=group pairs
+func ParseTierConfig(value string) (*TierConfig, error) {
+	config := &TierConfig{Groups: make(map[string]string)}
+	groups := make(map[string]bool)
+	for _, pair := range splitList(value) {
+		tier, group, ok := strings.Cut(pair, "=")
+		tier, group = strings.TrimSpace(tier), strings.Trim(strings.TrimSpace(group), "/")
+		if !ok || tier == "" || group == "" {
+			return nil, fmt.Errorf("invalid %s entry %q, expected tier=group", UserTierGroups, pair)
+		}
+		if _, ok := config.Groups[tier]; ok || groups[group] {
+			return nil, fmt.Errorf("duplicate %s entry %q", UserTierGroups, pair)
+		}
+		config.Groups[tier] = group
+		groups[group] = true
+	}
+	if len(config.Groups) == 0 {
+		return nil, fmt.Errorf("%s has no tiers", UserTierGroups)
+	}
+	return config, nil
+}
+
+// Tiers returns the configured tier names, sorted
+func (c *TierConfig) Tiers() []string {
+	tiers := make([]string, 0, len(c.Groups))
+	for tier := range c.Groups {
+		tiers = append(tiers, tier)
+	}
+	sort.Strings(tiers)
+	return tiers
+}
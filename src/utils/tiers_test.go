@@ -0,0 +1,39 @@
+package utils
+
+import "testing"
+
+func TestParseTierConfig(t *testing.T) {
+	config, err := ParseTierConfig("default=default-group, pro=/tiers/pro-group")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if config.Groups["default"] != "default-group" || config.Groups["pro"] != "tiers/pro-group" {
+		t.Errorf("unexpected groups %v", config.Groups)
+	}
+	if tiers := config.Tiers(); len(tiers) != 2 || tiers[0] != "default" {
+		t.Errorf("unexpected tiers %v", tiers)
+	}
+
+	for _, value := range []string{"", "pro", "pro=", "pro=a,pro=b", "a=g,b=g"} {
+		if _, err := ParseTierConfig(value); err == nil {
+			t.Errorf("expected %q to be rejected", value)
+		}
+	}
+}
+
+func TestNewTierConfigDefaultTier(t *testing.T) {
+	t.Setenv(UserTierGroups, "free=free-group,pro=pro-group")
+	t.Setenv(UserDefaultTier, "free")
+	config, err := NewTierConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if config.Default != "free" {
+		t.Errorf("expected default tier free, got %q", config.Default)
+	}
+
+	t.Setenv(UserDefaultTier, "default")
+	if _, err := NewTierConfig(); err == nil {
+		t.Error("expected an unknown default tier to be rejected")
+	}
+}
@@ -0,0 +1,268 @@
+package utils
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Nerzal/gocloak/v13"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/sync/singleflight"
+)
+
+// User cache environment variable keys
+const (
+	UserCacheTTL         = "USER_CACHE_TTL"
+	UserCacheNegativeTTL = "USER_CACHE_NEGATIVE_TTL"
+	UserCacheSize        = "USER_CACHE_SIZE"
+)
+
+const (
+	defaultUserCacheTTL         = 5 * time.Minute
+	defaultUserCacheNegativeTTL = 30 * time.Second
+	defaultUserCacheSize        = 10000
+	// userBatchLookups bounds concurrent Keycloak lookups of GetUserProfiles
+	userBatchLookups = 8
+	// userLookupTimeout bounds a shared lookup, which doesn't end when its callers give up
+	userLookupTimeout = 10 * time.Second
+)
+
+type userCacheEntry struct {
+	userID string
+	// user is nil for unknown IDs (negative entry)
+	user      *gocloak.User
+	expiresAt time.Time
+}
+
+// UserCacheStats are the counters of a UserCache
+type UserCacheStats struct {
+	Hits   uint64
+	Misses uint64
+	Size   int
+}
+
+// UserCache is an in-process LRU cache in front of CloakHelper.GetUserProfile.
+// Unknown IDs are cached for a shorter TTL and concurrent misses for the same ID share one lookup.
+// Cached users are shared between callers and must not be modified.
+type UserCache struct {
+	helper      *CloakHelper
+	ttl         time.Duration
+	negativeTTL time.Duration
+	capacity    int
+	// lookupTimeout bounds each Keycloak lookup
+	lookupTimeout time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List // front is most recently used
+	group   singleflight.Group
+	// generation counts invalidations, lookups started before one don't store their result
+	generation uint64
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+func NewUserCache(helper *CloakHelper) (*UserCache, error) {
+	cache := &UserCache{
+		helper:        helper,
+		ttl:           defaultUserCacheTTL,
+		negativeTTL:   defaultUserCacheNegativeTTL,
+		capacity:      defaultUserCacheSize,
+		lookupTimeout: userLookupTimeout,
+		entries:       make(map[string]*list.Element),
+		order:         list.New(),
+	}
+
+	var err error
+	if cache.ttl, err = DurationEnv(UserCacheTTL, cache.ttl); err != nil {
+		return nil, err
+	}
+	if cache.negativeTTL, err = DurationEnv(UserCacheNegativeTTL, cache.negativeTTL); err != nil {
+		return nil, err
+	}
+	if value := os.Getenv(UserCacheSize); value != "" {
+		if cache.capacity, err = strconv.Atoi(value); err != nil || cache.capacity <= 0 {
+			return nil, fmt.Errorf("invalid %s: %q", UserCacheSize, value)
+		}
+	}
+
+	return cache, nil
+}
+
+// GetUserProfile returns the cached user or fetches it from Keycloak
+func (c *UserCache) GetUserProfile(ctx context.Context, userID string) (*gocloak.User, error) {
+	if entry, ok := c.lookup(userID); ok {
+		c.hits.Add(1)
+		if entry.user == nil {
+			return nil, notFoundError(userID)
+		}
+		return entry.user, nil
+	}
+	c.misses.Add(1)
+
+	c.mu.Lock()
+	generation := c.generation
+	c.mu.Unlock()
+	lookup := c.group.DoChan(userID, func() (interface{}, error) {
+		// One caller giving up must not fail the others waiting on this lookup,
+		// but a hanging Keycloak must not block every later miss of the ID either
+		lookupCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.lookupTimeout)
+		defer cancel()
+		user, err := c.helper.GetUserProfile(lookupCtx, userID)
+		if err != nil {
+			if IsNotFound(err) {
+				c.store(userID, nil, c.negativeTTL, generation)
+			}
+			return nil, err
+		}
+		c.store(userID, user, c.ttl, generation)
+		return user, nil
+	})
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result := <-lookup:
+		if result.Err != nil {
+			return nil, result.Err
+		}
+		return result.Val.(*gocloak.User), nil
+	}
+}
+
+// GetUserProfiles looks up many users, each ID once and at most userBatchLookups at a time.
+// Unknown IDs are missing from the result, any other failure fails the whole batch.
+func (c *UserCache) GetUserProfiles(ctx context.Context, userIDs []string) (map[string]*gocloak.User, error) {
+	var mu sync.Mutex
+	users := make(map[string]*gocloak.User, len(userIDs))
+	seen := make(map[string]bool, len(userIDs))
+
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(userBatchLookups)
+	for _, userID := range userIDs {
+		if seen[userID] {
+			continue
+		}
+		seen[userID] = true
+
+		group.Go(func() error {
+			user, err := c.GetUserProfile(groupCtx, userID)
+			if IsNotFound(err) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			mu.Lock()
+			users[userID] = user
+			mu.Unlock()
+			return nil
+		})
+	}
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// Invalidate evicts one user. A lookup of the user already in flight neither stores
+// its result nor is shared with later misses, it may have read the old profile.
+func (c *UserCache) Invalidate(userID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	c.group.Forget(userID)
+	if element, ok := c.entries[userID]; ok {
+		c.order.Remove(element)
+		delete(c.entries, userID)
+	}
+}
+
+// Flush evicts every user, lookups already in flight don't store their result
+func (c *UserCache) Flush() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	c.entries = make(map[string]*list.Element)
+	c.order.Init()
+}
+
+func (c *UserCache) Stats() UserCacheStats {
+	c.mu.Lock()
+	size := len(c.entries)
+	c.mu.Unlock()
+	return UserCacheStats{
+		Hits:   c.hits.Load(),
+		Misses: c.misses.Load(),
+		Size:   size,
+	}
+}
+
+func (c *UserCache) lookup(userID string) (*userCacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[userID]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*userCacheEntry)
+	if time.Now().After(entry.expiresAt) {
+		c.order.Remove(element)
+		delete(c.entries, userID)
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+	return entry, true
+}
+
+// store caches the result of a lookup started at generation, unless the cache was invalidated since
+func (c *UserCache) store(userID string, user *gocloak.User, ttl time.Duration, generation uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if generation != c.generation {
+		return
+	}
+
+	entry := &userCacheEntry{userID: userID, user: user, expiresAt: time.Now().Add(ttl)}
+	if element, ok := c.entries[userID]; ok {
+		element.Value = entry
+		c.order.MoveToFront(element)
+		return
+	}
+	c.entries[userID] = c.order.PushFront(entry)
+
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*userCacheEntry).userID)
+	}
+}
+
+// notFoundError mimics the error Keycloak returns for unknown users
+func notFoundError(userID string) error {
+	return fmt.Errorf("failed to get user %s: %w", userID, &gocloak.APIError{
+		Code:    http.StatusNotFound,
+		Message: "404 Not Found: User not found",
+	})
+}
+
+// DurationEnv parses an optional duration environment variable, fallback is used when it is unset
+func DurationEnv(key string, fallback time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return duration, nil
+}
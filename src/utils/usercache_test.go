@@ -0,0 +1,241 @@
+package utils
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestUserCache(t *testing.T, kc *fakeKeycloak) *UserCache {
+	t.Helper()
+	cache, err := NewUserCache(kc.helper())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return cache
+}
+
+func TestUserCacheHitsAndMisses(t *testing.T) {
+	kc := newFakeKeycloak(t)
+	kc.addUser("u1", "alice")
+	cache := newTestUserCache(t, kc)
+
+	for i := 0; i < 3; i++ {
+		user, err := cache.GetUserProfile(context.Background(), "u1")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if *user.Username != "alice" {
+			t.Errorf("unexpected user %v", *user.Username)
+		}
+	}
+
+	if _, lookups := kc.counts(); lookups != 1 {
+		t.Errorf("expected a single lookup, got %d", lookups)
+	}
+	if stats := cache.Stats(); stats.Hits != 2 || stats.Misses != 1 || stats.Size != 1 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+
+	cache.Invalidate("u1")
+	if _, err := cache.GetUserProfile(context.Background(), "u1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, lookups := kc.counts(); lookups != 2 {
+		t.Errorf("expected an evicted user to be fetched again, got %d lookups", lookups)
+	}
+}
+
+func TestUserCacheNegativeEntries(t *testing.T) {
+	kc := newFakeKeycloak(t)
+	cache := newTestUserCache(t, kc)
+
+	for i := 0; i < 2; i++ {
+		if _, err := cache.GetUserProfile(context.Background(), "ghost"); !IsNotFound(err) {
+			t.Fatalf("expected not found, got %v", err)
+		}
+	}
+	if _, lookups := kc.counts(); lookups != 1 {
+		t.Errorf("expected unknown user to be cached, got %d lookups", lookups)
+	}
+
+	// Once the negative entry expired the user is fetched again
+	cache.negativeTTL = -time.Second
+	cache.Flush()
+	if _, err := cache.GetUserProfile(context.Background(), "ghost"); !IsNotFound(err) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+	kc.addUser("ghost", "casper")
+	user, err := cache.GetUserProfile(context.Background(), "ghost")
+	if err != nil || *user.Username != "casper" {
+		t.Errorf("expected expired negative entry to be refetched, got %v", err)
+	}
+}
+
+func TestUserCacheCoalescesMisses(t *testing.T) {
+	kc := newFakeKeycloak(t)
+	kc.addUser("u1", "alice")
+	cache := newTestUserCache(t, kc)
+
+	// Log in first so only the lookups race
+	if _, err := cache.helper.ServiceToken(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := cache.GetUserProfile(context.Background(), "u1"); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if _, lookups := kc.counts(); lookups != 1 {
+		t.Errorf("expected concurrent misses to share a lookup, got %d", lookups)
+	}
+}
+
+func TestUserCacheBoundsSharedLookups(t *testing.T) {
+	kc := newFakeKeycloak(t)
+	kc.addUser("u1", "alice")
+	kc.stalled = true
+	cache := newTestUserCache(t, kc)
+	cache.lookupTimeout = 200 * time.Millisecond
+
+	// A caller giving up stops waiting right away, the shared lookup goes on
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := cache.GetUserProfile(ctx, "u1"); err != context.DeadlineExceeded {
+		t.Errorf("expected the caller's deadline, got %v", err)
+	}
+
+	// The caller's deadline is dropped for the shared lookup, the lookup timeout still ends it
+	done := make(chan error, 1)
+	go func() {
+		_, err := cache.GetUserProfile(context.Background(), "u1")
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("expected the stalled lookup to fail")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the stalled lookup to time out")
+	}
+
+	// Later misses start a new lookup instead of joining the stalled one
+	kc.mu.Lock()
+	kc.stalled = false
+	kc.mu.Unlock()
+	if _, err := cache.GetUserProfile(context.Background(), "u1"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestUserCacheInvalidateDuringLookup(t *testing.T) {
+	kc := newFakeKeycloak(t)
+	kc.addUser("u1", "alice")
+	kc.lookupGate = make(chan struct{})
+	cache := newTestUserCache(t, kc)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		cache.GetUserProfile(context.Background(), "u1")
+	}()
+	for {
+		if _, lookups := kc.counts(); lookups == 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// The user changes while the lookup holds the old profile
+	kc.addUser("u1", "alice-renamed")
+	cache.Invalidate("u1")
+	kc.mu.Lock()
+	close(kc.lookupGate)
+	kc.lookupGate = nil
+	kc.mu.Unlock()
+	<-done
+
+	user, err := cache.GetUserProfile(context.Background(), "u1")
+	if err != nil || *user.Username != "alice-renamed" {
+		t.Errorf("expected the profile read after the invalidation, got %v: %v", user, err)
+	}
+}
+
+func TestUserCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	kc := newFakeKeycloak(t)
+	kc.addUser("u1", "alice")
+	kc.addUser("u2", "bob")
+	kc.addUser("u3", "carol")
+	cache := newTestUserCache(t, kc)
+	cache.capacity = 2
+
+	ctx := context.Background()
+	cache.GetUserProfile(ctx, "u1")
+	cache.GetUserProfile(ctx, "u2")
+	cache.GetUserProfile(ctx, "u1")
+	cache.GetUserProfile(ctx, "u3")
+
+	if _, ok := cache.lookup("u2"); ok {
+		t.Error("expected least recently used user to be evicted")
+	}
+	if _, ok := cache.lookup("u1"); !ok {
+		t.Error("expected recently used user to be kept")
+	}
+	if stats := cache.Stats(); stats.Size != 2 {
+		t.Errorf("expected 2 entries, got %d", stats.Size)
+	}
+}
+
+func TestUserCacheConfiguration(t *testing.T) {
+	t.Setenv(UserCacheTTL, "1m")
+	t.Setenv(UserCacheSize, "5")
+	cache, err := NewUserCache(&CloakHelper{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cache.ttl != time.Minute || cache.capacity != 5 || cache.negativeTTL != defaultUserCacheNegativeTTL {
+		t.Errorf("unexpected configuration %v %d %v", cache.ttl, cache.capacity, cache.negativeTTL)
+	}
+
+	t.Setenv(UserCacheSize, "0")
+	if _, err := NewUserCache(&CloakHelper{}); err == nil {
+		t.Error("expected invalid size to be rejected")
+	}
+}
+
+func TestUserCacheBatchLookup(t *testing.T) {
+	kc := newFakeKeycloak(t)
+	kc.addUser("u1", "alice")
+	kc.addUser("u2", "bob")
+	cache := newTestUserCache(t, kc)
+
+	users, err := cache.GetUserProfiles(context.Background(), []string{"u1", "ghost", "u2", "u1", "ghost"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(users) != 2 || *users["u1"].Username != "alice" || *users["u2"].Username != "bob" {
+		t.Errorf("unexpected users %v", users)
+	}
+	if _, lookups := kc.counts(); lookups != 3 {
+		t.Errorf("expected each distinct ID to be looked up once, got %d lookups", lookups)
+	}
+	if logins, _ := kc.counts(); logins != 1 {
+		t.Errorf("expected the service token to be reused, got %d logins", logins)
+	}
+
+	kc.server.Close()
+	cache.Flush()
+	if _, err := cache.GetUserProfiles(context.Background(), []string{"u1"}); err == nil {
+		t.Error("expected Keycloak failures to fail the batch")
+	}
+}
@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"fmt"
+	"net/mail"
+	"regexp"
+	"unicode/utf8"
+)
+
+// Limits of profile fields, Keycloak rejects longer values
+const (
+	minUsernameLength = 3
+	maxNameLength     = 255
+	maxEmailLength    = 254
+)
+
+// personNameChars mirrors Keycloak's person-name-prohibited-characters validator
+var personNameChars = regexp.MustCompile(`^[^<>&"\v$%!#?§;*~/\\|^=\[\]{}()\p{Cc}]*$`)
+
+// usernameChars mirrors Keycloak's username-prohibited-characters validator
+var usernameChars = regexp.MustCompile(`^[^<>&"'\s$%!#?§;*~/\\|^=\[\]{}()\p{Cc}]+$`)
+
+// ValidateUsername checks a new username
+func ValidateUsername(field, value string) error {
+	if length := utf8.RuneCountInString(value); length < minUsernameLength || length > maxNameLength {
+		return fmt.Errorf("%s must be between %d and %d characters", field, minUsernameLength, maxNameLength)
+	}
+	if !utf8.ValidString(value) || !usernameChars.MatchString(value) {
+		return fmt.Errorf("%s contains invalid characters", field)
+	}
+	return nil
+}
+
+// ValidateName checks a first or last name, empty names are allowed
+func ValidateName(field, value string) error {
+	if !utf8.ValidString(value) {
+		return fmt.Errorf("%s is not valid UTF-8", field)
+	}
+	if utf8.RuneCountInString(value) > maxNameLength {
+		return fmt.Errorf("%s must be at most %d characters", field, maxNameLength)
+	}
+	if !personNameChars.MatchString(value) {
+		return fmt.Errorf("%s contains invalid characters", field)
+	}
+	return nil
+}
+
+// ValidateEmail checks that value is a bare email address like alice@example.com
+func ValidateEmail(field, value string) error {
+	if value == "" {
+		return fmt.Errorf("%s is required", field)
+	}
+	if len(value) > maxEmailLength {
+		return fmt.Errorf("%s must be at most %d characters", field, maxEmailLength)
+	}
+	address, err := mail.ParseAddress(value)
+	if err != nil || address.Address != value {
+		return fmt.Errorf("%s is not a valid email address", field)
+	}
+	return nil
+}
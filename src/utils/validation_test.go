@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateName(t *testing.T) {
+	for _, name := range []string{"", "Alice", "Zoë", "O'Brien", "Jean-Luc", "李"} {
+		if err := ValidateName("firstname", name); err != nil {
+			t.Errorf("expected %q to be valid: %v", name, err)
+		}
+	}
+	for _, name := range []string{"<script>", "a/b", "tab\there", strings.Repeat("a", 256), "\xff"} {
+		if err := ValidateName("firstname", name); err == nil {
+			t.Errorf("expected %q to be rejected", name)
+		}
+	}
+}
+
+func TestValidateEmail(t *testing.T) {
+	if err := ValidateEmail("email", "alice@example.com"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	for _, email := range []string{"", "alice", "Alice <alice@example.com>", "alice@example.com ", strings.Repeat("a", 250) + "@x.io"} {
+		if err := ValidateEmail("email", email); err == nil {
+			t.Errorf("expected %q to be rejected", email)
+		}
+	}
+}
+
+func TestValidateUsername(t *testing.T) {
+	for _, username := range []string{"bob", "alice.smith", "a_b-c@example.com"} {
+		if err := ValidateUsername("username", username); err != nil {
+			t.Errorf("expected %q to be valid: %v", username, err)
+		}
+	}
+	for _, username := range []string{"", "ab", "with space", "semi;colon", "o'brien", strings.Repeat("a", 256)} {
+		if err := ValidateUsername("username", username); err == nil {
+			t.Errorf("expected %q to be rejected", username)
+		}
+	}
+}